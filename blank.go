@@ -0,0 +1,134 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// blankValues configures the substrings of optional fields that are decoded as the zero value or
+// as a default.
+type blankValues struct {
+	// omitEmpty is true if blank substrings are decoded as the zero value.
+	omitEmpty bool
+	// nullIf is the substring decoded as the zero value, if hasNullIf is true.
+	nullIf    string
+	hasNullIf bool
+	// defaultValue is decoded from blank substrings, if hasDefault is true.
+	defaultValue string
+	hasDefault   bool
+}
+
+// applyBlanks applies the "omitempty", "nullif" and "default" options.
+func (f *fieldPlan) applyBlanks(tag *fieldTag) error {
+	b := &blankValues{}
+	_, b.omitEmpty = tag.take(omitEmptyFlag)
+	b.nullIf, b.hasNullIf = tag.take(nullIfOption)
+	b.defaultValue, b.hasDefault = tag.take(defaultOption)
+
+	if !b.omitEmpty && !b.hasNullIf && !b.hasDefault {
+		return nil
+	}
+
+	err := f.checkBlanks(b)
+	if err != nil {
+		return err
+	}
+
+	f.blanks = b
+
+	return nil
+}
+
+// checkBlanks rejects conflicting or invalid "omitempty", "nullif" and "default" options.
+func (f *fieldPlan) checkBlanks(b *blankValues) error {
+	switch {
+	case b.omitEmpty && b.hasDefault:
+		return fmt.Errorf("options %q and %q are mutually exclusive", omitEmptyFlag, defaultOption)
+	case b.hasNullIf && b.nullIf == "":
+		return fmt.Errorf("invalid %q option %q", nullIfOption, b.nullIf)
+	default:
+		return f.checkDefault(b)
+	}
+}
+
+// checkDefault rejects "default" options that the field's valuer cannot decode.
+func (f *fieldPlan) checkDefault(b *blankValues) error {
+	if !b.hasDefault || f.valuer == nil || f.unmarshaler {
+		return nil
+	}
+
+	if _, err := f.valuer(b.defaultValue); err != nil {
+		return fmt.Errorf("invalid %q option %q: %w", defaultOption, b.defaultValue, err)
+	}
+
+	return nil
+}
+
+// decodeBlank decodes rawVal into fv if it is blank or the "nullif" value. It returns false if
+// rawVal must be decoded as usual.
+func (f *fieldPlan) decodeBlank(rawVal string, fv reflect.Value, o *options) (bool, error) {
+	b := f.blanks
+	blank := strings.TrimLeft(rawVal, " ") == ""
+
+	switch {
+	case b.hasNullIf && rawVal == b.nullIf, b.omitEmpty && blank:
+		fv.SetZero()
+
+		return true, nil
+	case b.hasDefault && blank:
+		return true, f.assign(b.defaultValue, rawVal, fv, o)
+	default:
+		return false, nil
+	}
+}
+
+// isBlank returns true if rawVal, the field's substring transcoded to UTF-8 if the field
+// transcodes, only holds spaces.
+func (f *fieldPlan) isBlank(rawVal string, o *options) bool {
+	space := byte(' ')
+	if !f.transcodes(o) {
+		space = codePageByte(space, o)
+	}
+
+	for i := 0; i < len(rawVal); i++ {
+		if rawVal[i] != space {
+			return false
+		}
+	}
+
+	return true
+}
+
+// encodesBlank returns true if fv is the zero value of a field with the "omitempty" flag or the
+// "nullif" option.
+func (f *fieldPlan) encodesBlank(fv reflect.Value) bool {
+	b := f.blanks
+
+	return b != nil && (b.omitEmpty || b.hasNullIf) && fv.IsZero()
+}
+
+// encodeBlank encodes the zero value of a field with the "omitempty" flag or the "nullif" option
+// as blanks or as the "nullif" value, padded to width.
+func (f *fieldPlan) encodeBlank(width int, o *options) (string, error) {
+	strVal, err := f.toCodePage(f.blanks.nullIf, o)
+	if err != nil {
+		return "", err
+	}
+
+	return pad(strVal, width, false, o), nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+type optionalRecord struct {
+	Count    *int             `strum:"0,3,omitempty"`
+	Limit    *int             `strum:"3,9,nullif=000000"`
+	Currency string           `strum:"9,12,default=USD"`
+	Amount   strum.MinorUnits `strum:"12,17,dec=2,default=0"`
+	Codes    [2]*int          `strum:"17,21,omitempty"`
+}
+
+func TestUnmarshal_blankValues(t *testing.T) { //nolint:funlen
+	t.Run("blank and sentinel values", func(t *testing.T) {
+		test := &optionalRecord{Count: new(int), Limit: new(int)}
+
+		err := strum.Unmarshal("   000000        12  ", test)
+		require.NoError(t, err)
+		require.Nil(t, test.Count)
+		require.Nil(t, test.Limit)
+		require.Equal(t, "USD", test.Currency)
+		require.Equal(t, strum.MinorUnits(0), test.Amount)
+		require.Equal(t, 12, *test.Codes[0])
+		require.Nil(t, test.Codes[1])
+	})
+
+	t.Run("other values", func(t *testing.T) {
+		test := &optionalRecord{}
+
+		err := strum.Unmarshal("042000100CAD0123400  ", test)
+		require.NoError(t, err)
+		require.Equal(t, 42, *test.Count)
+		require.Equal(t, 100, *test.Limit)
+		require.Equal(t, "CAD", test.Currency)
+		require.Equal(t, strum.MinorUnits(1234), test.Amount)
+		require.Equal(t, 0, *test.Codes[0])
+		require.Nil(t, test.Codes[1])
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		line, err := strum.Marshal(optionalRecord{Currency: "EUR"})
+		require.NoError(t, err)
+		require.Equal(t, "   000000EUR00000    ", line)
+
+		test := &optionalRecord{}
+		require.NoError(t, strum.Unmarshal(line, test))
+		require.Equal(t, optionalRecord{Currency: "EUR"}, *test)
+	})
+
+	t.Run("nested structs", func(t *testing.T) {
+		test := &struct {
+			Home *address `strum:"0,omitempty"`
+		}{}
+
+		require.NoError(t, strum.Unmarshal("        ", test))
+		require.Nil(t, test.Home)
+
+		require.NoError(t, strum.Unmarshal("123Main ", test))
+		require.Equal(t, &address{Number: 123, Street: "Main "}, test.Home)
+	})
+
+	t.Run("formatters do not apply to defaults", func(t *testing.T) {
+		test := &struct {
+			Val int `strum:"0,3,default=7" strform:"trim"`
+		}{}
+
+		err := strum.Unmarshal("   ", test, strum.WithFormatter("trim", func(string) (string, error) {
+			return "", nil
+		}))
+		require.NoError(t, err)
+		require.Equal(t, 7, test.Val)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		err := strum.Unmarshal("abc", &struct {
+			Val *int `strum:"0,3,omitempty,default=1"`
+		}{})
+		require.ErrorIs(t, err, strum.ErrInvalidTag)
+		require.ErrorContains(t, err, `options "omitempty" and "default" are mutually exclusive`)
+
+		err = strum.Unmarshal("abc", &struct {
+			Val int `strum:"0,3,default=x"`
+		}{})
+		require.ErrorIs(t, err, strum.ErrInvalidTag)
+		require.ErrorContains(t, err, `invalid "default" option "x"`)
+
+		err = strum.Unmarshal("abc", &struct {
+			Val int `strum:"0,3,nullif="`
+		}{})
+		require.ErrorContains(t, err, `invalid "nullif" option ""`)
+
+		err = strum.Unmarshal(" 1 ", &struct {
+			Val int `strum:"0,3,omitempty"`
+		}{})
+		require.ErrorIs(t, err, strum.ErrParse)
+	})
+}
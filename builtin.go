@@ -15,8 +15,10 @@
 package strum
 
 import (
+	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 )
 
 type primitiveValuer func(s string) (reflect.Value, error)
@@ -209,3 +211,226 @@ func castFloat[T numeric](n float64, err error) (T, error) {
 
 	return T(n), nil
 }
+
+type primitiveEncoder func(v reflect.Value) string
+
+var builtinEncoders = map[reflect.Kind]primitiveEncoder{
+	reflect.Bool: func(v reflect.Value) string {
+		return strconv.FormatBool(v.Bool())
+	},
+	reflect.Int:    encodeInt,
+	reflect.Int8:   encodeInt,
+	reflect.Int16:  encodeInt,
+	reflect.Int32:  encodeInt,
+	reflect.Int64:  encodeInt,
+	reflect.Uint:   encodeUint,
+	reflect.Uint8:  encodeUint,
+	reflect.Uint16: encodeUint,
+	reflect.Uint32: encodeUint,
+	reflect.Uint64: encodeUint,
+	reflect.Float32: func(v reflect.Value) string {
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32)
+	},
+	reflect.Float64: func(v reflect.Value) string {
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	},
+	reflect.String: func(v reflect.Value) string {
+		return v.String()
+	},
+}
+
+func encodeInt(v reflect.Value) string {
+	return strconv.FormatInt(v.Int(), 10)
+}
+
+func encodeUint(v reflect.Value) string {
+	return strconv.FormatUint(v.Uint(), 10)
+}
+
+func isInteger(k reflect.Kind) bool {
+	switch k { //nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isNumeric(k reflect.Kind) bool {
+	return isInteger(k) || k == reflect.Float32 || k == reflect.Float64
+}
+
+// decimalValuer returns a valuer for numeric fields of type t, or pointers to them, whose values
+// have dec implied decimal places. Integer fields are assigned the integer part of the value and
+// reject values with a fractional part, except for MinorUnits which are assigned every digit.
+func decimalValuer(t reflect.Type, dec int) (primitiveValuer, bool) {
+	valuers, ptr := builtin, t.Kind() == reflect.Ptr
+	if ptr {
+		t = t.Elem()
+		valuers = builtinPointers
+	}
+
+	valuer := valuers[t.Kind()]
+
+	switch {
+	case t == minorUnitsType:
+		return minorUnitsValuer(ptr, dec), true
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return floatDecimalValuer(valuer, dec), true
+	case isInteger(t.Kind()):
+		return integerDecimalValuer(valuer, dec), true
+	default:
+		return nil, false
+	}
+}
+
+func floatDecimalValuer(valuer primitiveValuer, dec int) primitiveValuer {
+	return func(s string) (reflect.Value, error) {
+		sign, integer, fraction, err := impliedDecimal(s, dec)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		return valuer(sign + integer + "." + fraction)
+	}
+}
+
+func integerDecimalValuer(valuer primitiveValuer, dec int) primitiveValuer {
+	return func(s string) (reflect.Value, error) {
+		sign, integer, fraction, err := impliedDecimal(s, dec)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		if strings.Trim(fraction, "0") != "" {
+			return reflect.Value{}, fmt.Errorf("fractional part %q cannot be assigned", fraction)
+		}
+
+		return valuer(sign + integer)
+	}
+}
+
+func minorUnitsValuer(ptr bool, dec int) primitiveValuer {
+	return func(s string) (reflect.Value, error) {
+		sign, integer, fraction, err := impliedDecimal(s, dec)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		if len(fraction) > dec {
+			if strings.Trim(fraction[dec:], "0") != "" {
+				return reflect.Value{}, fmt.Errorf("more than %d decimal places", dec)
+			}
+
+			fraction = fraction[:dec]
+		}
+
+		fraction += strings.Repeat("0", dec-len(fraction))
+
+		n, err := strconv.ParseInt(sign+integer+fraction, 10, 64)
+		m := MinorUnits(n)
+
+		if ptr {
+			return valueOrError(&m, err)
+		}
+
+		return valueOrError(m, err)
+	}
+}
+
+// decimalEncoder returns an encoder for numeric fields of type t, or pointers to them, whose
+// values have dec implied decimal places.
+func decimalEncoder(t reflect.Type, dec int) primitiveEncoder {
+	elem := t
+	if t.Kind() == reflect.Ptr {
+		elem = t.Elem()
+	}
+
+	var encoder primitiveEncoder
+
+	switch {
+	case elem == minorUnitsType:
+		encoder = encodeInt
+	case elem.Kind() == reflect.Float32 || elem.Kind() == reflect.Float64:
+		encoder = func(v reflect.Value) string {
+			s := strconv.FormatFloat(v.Float(), 'f', dec, elem.Bits())
+
+			return strings.Replace(s, ".", "", 1)
+		}
+	default:
+		base := builtinEncoders[elem.Kind()]
+		encoder = func(v reflect.Value) string {
+			s := base(v)
+			if s == "0" {
+				return s
+			}
+
+			return s + strings.Repeat("0", dec)
+		}
+	}
+
+	if t.Kind() == reflect.Ptr {
+		return ptrEncoder(encoder)
+	}
+
+	return encoder
+}
+
+// ptrEncoder encodes pointers with the given encoder. Nil pointers are encoded as empty values,
+// which are padded with spaces.
+func ptrEncoder(encoder primitiveEncoder) primitiveEncoder {
+	return func(v reflect.Value) string {
+		if v.IsNil() {
+			return ""
+		}
+
+		return encoder(v.Elem())
+	}
+}
+
+// bytesValuer returns the valuer for slices of bytes of type t, and whether t is a slice of bytes.
+func bytesValuer(t reflect.Type) (primitiveValuer, bool) {
+	if t.Elem().Kind() != reflect.Uint8 {
+		return nil, false
+	}
+
+	return func(s string) (reflect.Value, error) {
+		return reflect.ValueOf([]byte(s)), nil
+	}, true
+}
+
+// byteArrayValuer returns the valuer for arrays of bytes of type t, which hold raw bytes like
+// []byte, and whether t is an array of bytes. Substrings shorter than the array leave its last
+// bytes to zero.
+func byteArrayValuer(t reflect.Type) (primitiveValuer, bool) {
+	if t.Elem().Kind() != reflect.Uint8 {
+		return nil, false
+	}
+
+	return func(s string) (reflect.Value, error) {
+		if len(s) > t.Len() {
+			return reflect.Value{}, fmt.Errorf("%d bytes do not fit %s", len(s), t)
+		}
+
+		v := reflect.New(t).Elem()
+		reflect.Copy(v, reflect.ValueOf([]byte(s)))
+
+		return v, nil
+	}, true
+}
+
+// byteArrayEncoder returns the encoder for arrays of bytes of type t, and whether t is numeric
+// and an array of bytes.
+func byteArrayEncoder(t reflect.Type) (primitiveEncoder, bool, bool) {
+	if t.Elem().Kind() != reflect.Uint8 {
+		return nil, false, false
+	}
+
+	return func(v reflect.Value) string {
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+
+		return string(b)
+	}, false, true
+}
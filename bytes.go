@@ -0,0 +1,122 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+// WithAliasedBytes makes UnmarshalBytes assign subslices of its input to []byte fields instead
+// of copies. The caller must then neither modify nor reuse the input while the decoded values are
+// in use. Values that were transcoded or formatted are not affected.
+func WithAliasedBytes() Option {
+	return func(o *options) {
+		o.aliasBytes = true
+	}
+}
+
+// UnmarshalBytes is like Unmarshal but decodes a []byte line without copying it, e.g. the lines
+// of a bufio.Scanner: substrings are only copied when assigned to string and []byte fields (see
+// WithAliasedBytes) or passed to FieldUnmarshaler and encoding.TextUnmarshaler implementations,
+// so that the decoded values do not reference line. These substrings share a single copy of
+// line, which is made at most once. Formatters receive substrings of line and must not retain
+// them.
+func UnmarshalBytes(line []byte, v any, opts ...Option) error {
+	options := *defaultOptions
+
+	for i := range opts {
+		opts[i](&options)
+	}
+
+	options.buffer = line
+
+	err := unmarshal(unsafe.String(unsafe.SliceData(line), len(line)), v, &options)
+
+	return detachErrors(err)
+}
+
+// isText returns true if t is a string, *string or []byte.
+func isText(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.String ||
+		(t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8)
+}
+
+// borrows returns true if s references the line given to UnmarshalBytes.
+func (o *options) borrows(s string) bool {
+	if len(o.buffer) == 0 || len(s) == 0 {
+		return false
+	}
+
+	start := uintptr(unsafe.Pointer(unsafe.SliceData(o.buffer)))
+	p := uintptr(unsafe.Pointer(unsafe.StringData(s)))
+
+	return p >= start && p < start+uintptr(len(o.buffer))
+}
+
+// detach returns s, a substring of the line given to UnmarshalBytes, sliced from a copy of the
+// line instead. The copy is made on first use and shared by the following substrings.
+func (o *options) detach(s string) string {
+	if o.detached == "" {
+		o.detached = string(o.buffer)
+	}
+
+	offset := uintptr(unsafe.Pointer(unsafe.StringData(s))) -
+		uintptr(unsafe.Pointer(unsafe.SliceData(o.buffer)))
+
+	return o.detached[offset : offset+uintptr(len(s))]
+}
+
+// alias assigns s, a substring of the line given to UnmarshalBytes, to the []byte field fv
+// without copying it if WithAliasedBytes is given. It returns false if s was not assigned.
+func (f *fieldPlan) alias(s string, fv reflect.Value, o *options) bool {
+	if !o.aliasBytes || f.unmarshaler || fv.Kind() != reflect.Slice {
+		return false
+	}
+
+	fv.SetBytes(unsafe.Slice(unsafe.StringData(s), len(s)))
+
+	return true
+}
+
+// detachErrors copies the values of the FieldErrors in err, which reference the line given to
+// UnmarshalBytes.
+func detachErrors(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var errs FieldErrors
+	if errors.As(err, &errs) {
+		for _, e := range errs {
+			e.Value = strings.Clone(e.Value)
+		}
+
+		return err
+	}
+
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		fieldErr.Value = strings.Clone(fieldErr.Value)
+	}
+
+	return err
+}
@@ -0,0 +1,131 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+type bytesRecord struct {
+	Name   string  `strum:"0,3"`
+	Ref    *string `strum:"3,5"`
+	Raw    []byte  `strum:"5,8"`
+	Amount int     `strum:"8,11"`
+}
+
+func TestUnmarshalBytes(t *testing.T) { //nolint:funlen
+	t.Run("copies strings and bytes", func(t *testing.T) {
+		line := []byte("abcdexyz042")
+		test := &bytesRecord{}
+
+		err := strum.UnmarshalBytes(line, test)
+		require.NoError(t, err)
+
+		copy(line, "ZZZZZZZZZZZ")
+
+		require.Equal(t, "abc", test.Name)
+		require.Equal(t, "de", *test.Ref)
+		require.Equal(t, []byte("xyz"), test.Raw)
+		require.Equal(t, 42, test.Amount)
+	})
+
+	t.Run("aliased bytes", func(t *testing.T) {
+		line := []byte("abcdexyz042")
+		test := &bytesRecord{}
+
+		err := strum.UnmarshalBytes(line, test, strum.WithAliasedBytes())
+		require.NoError(t, err)
+		require.Equal(t, 3, cap(test.Raw))
+
+		copy(line, "ZZZZZZZZZZZ")
+
+		require.Equal(t, "abc", test.Name)
+		require.Equal(t, []byte("ZZZ"), test.Raw)
+	})
+
+	t.Run("formatted values are not aliased", func(t *testing.T) {
+		line := []byte("abc")
+		test := &struct {
+			Raw []byte `strum:"0,3" strform:"upper"`
+		}{}
+
+		err := strum.UnmarshalBytes(line, test, strum.WithAliasedBytes(),
+			strum.WithFormatter("upper", func(s string) (string, error) {
+				return string(append([]byte{}, s[0]-32, s[1]-32, s[2]-32)), nil
+			}))
+		require.NoError(t, err)
+
+		copy(line, "ZZZ")
+
+		require.Equal(t, []byte("ABC"), test.Raw)
+	})
+
+	t.Run("unmarshalers receive copies", func(t *testing.T) {
+		line := []byte("abc")
+		test := &struct {
+			Val retainer `strum:"0,3"`
+		}{}
+
+		err := strum.UnmarshalBytes(line, test)
+		require.NoError(t, err)
+
+		copy(line, "ZZZ")
+
+		require.Equal(t, "abc", test.Val.s)
+	})
+
+	t.Run("error values are copied", func(t *testing.T) {
+		line := []byte("abcdexyzabc")
+
+		err := strum.UnmarshalBytes(line, &bytesRecord{})
+		require.ErrorIs(t, err, strum.ErrParse)
+
+		copy(line, "ZZZZZZZZZZZ")
+
+		var fieldErr *strum.FieldError
+		require.True(t, errors.As(err, &fieldErr))
+		require.Equal(t, "abc", fieldErr.Value)
+
+		err = strum.UnmarshalBytes(line, &bytesRecord{}, strum.WithAllErrors())
+		copy(line, "YYYYYYYYYYY")
+
+		var errs strum.FieldErrors
+		require.True(t, errors.As(err, &errs))
+		require.Equal(t, "ZZZ", errs[0].Value)
+	})
+
+	t.Run("same errors as Unmarshal", func(t *testing.T) {
+		err := strum.UnmarshalBytes([]byte("abc"), bytesRecord{})
+		require.ErrorIs(t, err, strum.ErrNotPointer)
+
+		err = strum.UnmarshalBytes(nil, &bytesRecord{})
+		require.ErrorIs(t, err, strum.ErrOutOfBounds)
+	})
+}
+
+type retainer struct {
+	s string
+}
+
+func (r *retainer) UnmarshalStrum(s string, _ strum.Field) error {
+	r.s = s
+
+	return nil
+}
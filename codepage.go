@@ -0,0 +1,180 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Names of the builtin code pages.
+const (
+	// IBM037 is EBCDIC code page 37 (US/Canada).
+	IBM037 = "IBM037"
+	// IBM500 is EBCDIC code page 500 (International).
+	IBM500 = "IBM500"
+	// IBM1047 is EBCDIC code page 1047 (Latin-1 open systems).
+	IBM1047 = "IBM1047"
+	// ISO88591 is ISO-8859-1 (Latin-1).
+	ISO88591 = "ISO-8859-1"
+	// Windows1252 is Windows code page 1252 (Western European).
+	Windows1252 = "windows-1252"
+)
+
+// CodePage maps every byte of a single-byte character encoding to a rune.
+type CodePage [256]rune
+
+// codePage is a registered CodePage along with its inverse.
+type codePage struct {
+	name  string
+	runes CodePage
+	bytes map[rune]byte
+}
+
+var codePages = struct {
+	sync.RWMutex
+	m map[string]*codePage
+}{m: make(map[string]*codePage)}
+
+func init() {
+	var latin1 CodePage
+	for i := range latin1 {
+		latin1[i] = rune(i)
+	}
+
+	RegisterCodePage(IBM037, &cp037)
+	RegisterCodePage(IBM500, &cp500)
+	RegisterCodePage(IBM1047, &cp1047)
+	RegisterCodePage(ISO88591, &latin1)
+	RegisterCodePage(Windows1252, &windows1252)
+}
+
+// RegisterCodePage makes the given CodePage available to WithCodePage under the given name,
+// replacing any code page previously registered under that name. It is safe for concurrent use.
+func RegisterCodePage(name string, cp *CodePage) {
+	c := &codePage{
+		name:  name,
+		runes: *cp,
+		bytes: make(map[rune]byte, len(cp)),
+	}
+
+	for i := len(cp) - 1; i >= 0; i-- {
+		// the lowest byte wins when several bytes map to the same rune
+		c.bytes[cp[i]] = byte(i)
+	}
+
+	codePages.Lock()
+	defer codePages.Unlock()
+
+	codePages.m[name] = c
+}
+
+// WithCodePage decodes lines from, and encodes lines to, the code page registered under the given
+// name (see RegisterCodePage) instead of UTF-8. Unmarshal and Marshal fail if no code page is
+// registered under that name.
+//
+// Fields are sliced from the original bytes of the line before being transcoded, so that indexes
+// refer to the record as it was received. Packed decimal fields are never transcoded.
+func WithCodePage(name string) Option {
+	return func(o *options) {
+		codePages.RLock()
+		defer codePages.RUnlock()
+
+		o.codePageName = name
+		o.codePage = codePages.m[name]
+	}
+}
+
+// validateCodePage returns an error if the code page selected with WithCodePage is unknown.
+func validateCodePage(o *options) error {
+	if o.codePageName != "" && o.codePage == nil {
+		return fmt.Errorf("unknown code page %q", o.codePageName)
+	}
+
+	return nil
+}
+
+// decode transcodes s from the code page to UTF-8.
+func (c *codePage) decode(s string) string {
+	var b strings.Builder
+
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		b.WriteRune(c.runes[s[i]])
+	}
+
+	return b.String()
+}
+
+// encode transcodes s from UTF-8 to the code page.
+func (c *codePage) encode(s string) (string, error) {
+	b := make([]byte, 0, len(s))
+
+	for _, r := range s {
+		cb, ok := c.bytes[r]
+		if !ok {
+			return "", fmt.Errorf("character %q cannot be encoded in code page %q", r, c.name)
+		}
+
+		b = append(b, cb)
+	}
+
+	return string(b), nil
+}
+
+// transcodes returns true if the values of f are transcoded by the code page in o. Nested and
+// repeated fields are transcoded field by field and packed decimal is binary.
+func (f *fieldPlan) transcodes(o *options) bool {
+	return o.codePage != nil && f.nested == nil && f.elem == nil && f.packing != packedDecimal
+}
+
+// fromCodePage transcodes the field's substring rawVal to UTF-8.
+func (f *fieldPlan) fromCodePage(rawVal string, o *options) string {
+	if !f.transcodes(o) {
+		return rawVal
+	}
+
+	return o.codePage.decode(rawVal)
+}
+
+// toCodePage transcodes the field's encoded value strVal from UTF-8.
+func (f *fieldPlan) toCodePage(strVal string, o *options) (string, error) {
+	if !f.transcodes(o) {
+		return strVal, nil
+	}
+
+	encoded, err := o.codePage.encode(strVal)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode field %q: %w", f.name, err)
+	}
+
+	return encoded, nil
+}
+
+// codePageByte returns the ASCII character c transcoded to the code page in o.
+func codePageByte(c byte, o *options) byte {
+	if o.codePage == nil {
+		return c
+	}
+
+	b, ok := o.codePage.bytes[rune(c)]
+	if !ok {
+		return c
+	}
+
+	return b
+}
@@ -0,0 +1,153 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+type ebcdicRecord struct {
+	Name    string           `strum:"0,5"`
+	Code    []string         `strum:"5,9,occurs=2"`
+	Amount  int              `strum:"9,12,zoned"`
+	Balance strum.MinorUnits `strum:"12,14,packed,dec=2"`
+	Nested  struct {
+		Val string `strum:"0,2"`
+	} `strum:"14,16"`
+}
+
+// ebcdicLine is ebcdicRecord{"HELLO", {"A1", "B2"}, -121, 2.34, {"é["}} in IBM037.
+const ebcdicLine = "\xc8\xc5\xd3\xd3\xd6\xc1\xf1\xc2\xf2\xf1\xf2\xd1\x23\x4c\x51\xba"
+
+func TestUnmarshal_codePage(t *testing.T) { //nolint:funlen
+	t.Run("transcodes each field after slicing", func(t *testing.T) {
+		test := &ebcdicRecord{}
+
+		err := strum.Unmarshal(ebcdicLine, test, strum.WithCodePage(strum.IBM037))
+		require.NoError(t, err)
+		require.Equal(t, "HELLO", test.Name)
+		require.Equal(t, []string{"A1", "B2"}, test.Code)
+		require.Equal(t, -121, test.Amount)
+		require.Equal(t, strum.MinorUnits(234), test.Balance)
+		require.Equal(t, "é[", test.Nested.Val)
+	})
+
+	t.Run("builtin code pages", func(t *testing.T) {
+		tests := map[string]struct {
+			line     string
+			expected string
+		}{
+			strum.IBM037:      {"\xba\xbb\x5f", "[]¬"},
+			strum.IBM500:      {"\x4a\x5a\x5f", "[]^"},
+			strum.IBM1047:     {"\xad\xbd\x5f", "[]^"},
+			strum.ISO88591:    {"\xe9\x80\xff", "é\u0080ÿ"},
+			strum.Windows1252: {"\xe9\x80\x81", "é€\u0081"},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				r := &struct {
+					Val   string `strum:"0,3"`
+					After string `strum:"3"`
+				}{}
+
+				err := strum.Unmarshal(test.line+"\xf0", r, strum.WithCodePage(name))
+				require.NoError(t, err)
+				require.Equal(t, test.expected, r.Val)
+				require.Len(t, []rune(r.After), 1)
+			})
+		}
+	})
+
+	t.Run("registered code page", func(t *testing.T) {
+		var rot strum.CodePage
+
+		for i := range rot {
+			rot[i] = rune(i)
+		}
+
+		rot['a'], rot['b'] = 'b', 'a'
+
+		strum.RegisterCodePage("test-rot", &rot)
+
+		test := &struct {
+			Val string `strum:"0"`
+		}{}
+
+		err := strum.Unmarshal("abc", test, strum.WithCodePage("test-rot"))
+		require.NoError(t, err)
+		require.Equal(t, "bac", test.Val)
+
+		line, err := strum.Marshal(test, strum.WithCodePage("test-rot"))
+		require.NoError(t, err)
+		require.Equal(t, "abc", line)
+	})
+
+	t.Run("discriminators of record sets", func(t *testing.T) {
+		rs := strum.NewRecordSet(0, 1, strum.WithCodePage(strum.IBM037)).
+			Register("A", struct {
+				Val int `strum:"1"`
+			}{})
+
+		v, err := rs.Unmarshal("\xc1\xf4\xf2")
+		require.NoError(t, err)
+		require.Equal(t, struct {
+			Val int `strum:"1"`
+		}{42}, v)
+	})
+
+	t.Run("error on unknown code page", func(t *testing.T) {
+		err := strum.Unmarshal("", &ebcdicRecord{}, strum.WithCodePage("unknown"))
+		require.ErrorContains(t, err, `unknown code page "unknown"`)
+
+		_, err = strum.Marshal(ebcdicRecord{}, strum.WithCodePage("unknown"))
+		require.ErrorContains(t, err, `unknown code page "unknown"`)
+	})
+}
+
+func TestMarshal_codePage(t *testing.T) {
+	t.Run("transcodes each field and the padding", func(t *testing.T) {
+		test := ebcdicRecord{
+			Name:    "HELLO",
+			Code:    []string{"A1", "B2"},
+			Amount:  -121,
+			Balance: 234,
+		}
+		test.Nested.Val = "é["
+
+		line, err := strum.Marshal(test, strum.WithCodePage(strum.IBM037))
+		require.NoError(t, err)
+		require.Equal(t, ebcdicLine, line)
+
+		padded, err := strum.Marshal(&struct {
+			Name   string `strum:"0,3"`
+			Amount int    `strum:"5,9"`
+		}{"A", -12}, strum.WithCodePage(strum.IBM037))
+		require.NoError(t, err)
+		require.Equal(t, "\xc1\x40\x40\x40\x40\x60\xf0\xf1\xf2", padded)
+	})
+
+	t.Run("error when a character cannot be encoded", func(t *testing.T) {
+		_, err := strum.Marshal(&struct {
+			Val string `strum:"0,1"`
+		}{"€"}, strum.WithCodePage(strum.IBM037))
+		require.ErrorContains(t, err, `cannot encode field "Val"`)
+		require.ErrorContains(t, err, `character '€' cannot be encoded in code page "IBM037"`)
+	})
+}
@@ -0,0 +1,62 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MinorUnits is an exact monetary amount expressed in minor units, eg. cents.
+//
+// Combined with the "dec" option, MinorUnits fields keep every digit of the amount without going
+// through floating point: "000000012345" with "dec=2" is 12345, and so is "123.45".
+type MinorUnits int64
+
+var minorUnitsType = reflect.TypeFor[MinorUnits]()
+
+// impliedDecimal splits s, a number with dec implied decimal places and an optional leading or
+// trailing sign, into its sign, integer and fractional parts. If s has an explicit decimal point
+// then dec is ignored. Values without any digit are rejected.
+func impliedDecimal(s string, dec int) (string, string, string, error) {
+	var sign string
+
+	switch {
+	case strings.HasPrefix(s, "-"), strings.HasPrefix(s, "+"):
+		sign, s = s[:1], s[1:]
+	case strings.HasSuffix(s, "-"), strings.HasSuffix(s, "+"):
+		sign, s = s[len(s)-1:], s[:len(s)-1]
+	}
+
+	if !strings.ContainsAny(s, "0123456789") {
+		return "", "", "", fmt.Errorf("no digits in %q", s)
+	}
+
+	integer, fraction, ok := strings.Cut(s, ".")
+	if !ok {
+		if len(s) < dec {
+			s = strings.Repeat("0", dec-len(s)) + s
+		}
+
+		integer, fraction = s[:len(s)-dec], s[len(s)-dec:]
+	}
+
+	if integer == "" {
+		integer = "0"
+	}
+
+	return sign, integer, fraction, nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+func TestUnmarshal_decimals(t *testing.T) { //nolint:funlen
+	t.Run("floats", func(t *testing.T) {
+		test := &struct {
+			F64 float64  `strum:"0,12,dec=2"`
+			F32 *float32 `strum:"12,16,dec=3"`
+		}{}
+
+		err := strum.Unmarshal("0000000123451500", test)
+		require.NoError(t, err)
+		require.InDelta(t, 123.45, test.F64, 0)
+		require.InDelta(t, float32(1.5), *test.F32, 0)
+	})
+
+	t.Run("signs", func(t *testing.T) {
+		test := &struct {
+			Leading  float64 `strum:"0,6,dec=2"`
+			Trailing float64 `strum:"6,12,dec=2"`
+		}{}
+
+		err := strum.Unmarshal("-0012300123-", test)
+		require.NoError(t, err)
+		require.InDelta(t, -1.23, test.Leading, 0)
+		require.InDelta(t, -1.23, test.Trailing, 0)
+	})
+
+	t.Run("integers must not have a fractional part", func(t *testing.T) {
+		test := &struct {
+			Val int64 `strum:"0,6,dec=2"`
+			Ptr *uint `strum:"6,8,dec=2"`
+		}{}
+
+		err := strum.Unmarshal("01230000", test)
+		require.NoError(t, err)
+		require.Equal(t, int64(123), test.Val)
+		require.Equal(t, uint(0), *test.Ptr)
+
+		err = strum.Unmarshal("01234500", test)
+		require.ErrorContains(t, err, `fractional part "45" cannot be assigned`)
+	})
+
+	t.Run("minor units keep every digit", func(t *testing.T) {
+		test := &struct {
+			Implied  strum.MinorUnits  `strum:"0,12,dec=2"`
+			Explicit *strum.MinorUnits `strum:"12,18,dec=2"`
+			Plain    strum.MinorUnits  `strum:"18,22"`
+		}{}
+
+		err := strum.Unmarshal("000000012345-123.40042", test)
+		require.NoError(t, err)
+		require.Equal(t, strum.MinorUnits(12345), test.Implied)
+		require.Equal(t, strum.MinorUnits(-12340), *test.Explicit)
+		require.Equal(t, strum.MinorUnits(42), test.Plain)
+	})
+
+	t.Run("minor units reject extra decimal places", func(t *testing.T) {
+		test := &struct {
+			Val strum.MinorUnits `strum:"0,dec=2"`
+		}{}
+
+		err := strum.Unmarshal("1.234", test)
+		require.ErrorContains(t, err, "more than 2 decimal places")
+
+		err = strum.Unmarshal("1.230", test)
+		require.NoError(t, err)
+		require.Equal(t, strum.MinorUnits(123), test.Val)
+	})
+
+	t.Run("error when there are no digits", func(t *testing.T) {
+		tests := map[string]any{
+			"int": &struct {
+				Val int `strum:"0,dec=2"`
+			}{},
+			"float": &struct {
+				Val float64 `strum:"0,dec=2"`
+			}{},
+			"minor units": &struct {
+				Val strum.MinorUnits `strum:"0,dec=2"`
+			}{},
+		}
+
+		for name, test := range tests {
+			for _, line := range []string{"", "-", "+", "."} {
+				err := strum.Unmarshal(line, test)
+				require.ErrorIs(t, err, strum.ErrParse, "%s %q", name, line)
+				require.ErrorContains(t, err, "no digits", "%s %q", name, line)
+			}
+		}
+	})
+
+	t.Run("applies to each occurrence", func(t *testing.T) {
+		test := &struct {
+			Val []strum.MinorUnits `strum:"0,occurs=2,elem=4,dec=2"`
+		}{}
+
+		err := strum.Unmarshal("01000250", test)
+		require.NoError(t, err)
+		require.Equal(t, []strum.MinorUnits{100, 250}, test.Val)
+	})
+
+	t.Run("error when the option is invalid", func(t *testing.T) {
+		tests := map[string]any{
+			`invalid "dec" option "x"`: &struct {
+				Val int `strum:"0,dec=x"`
+			}{},
+			`invalid "dec" option "-1"`: &struct {
+				Val int `strum:"0,dec=-1"`
+			}{},
+			"only supported on numeric fields": &struct {
+				Val string `strum:"0,dec=2"`
+			}{},
+		}
+
+		for expected, test := range tests {
+			err := strum.Unmarshal("0102", test)
+			require.ErrorContains(t, err, expected)
+		}
+	})
+}
+
+func TestMarshal_decimals(t *testing.T) {
+	type record struct {
+		Float    float64           `strum:"0,6,dec=2"`
+		Int      int               `strum:"6,10,dec=2"`
+		Minor    strum.MinorUnits  `strum:"10,16,dec=2"`
+		MinorPtr *strum.MinorUnits `strum:"16,22,dec=2"`
+		Zero     int               `strum:"22,24,dec=2"`
+	}
+
+	minor := strum.MinorUnits(-5)
+	expected := record{
+		Float:    -1.5,
+		Int:      12,
+		Minor:    12345,
+		MinorPtr: &minor,
+	}
+
+	line, err := strum.Marshal(expected)
+	require.NoError(t, err)
+	require.Equal(t, "-001501200012345-0000500", line)
+
+	var actual record
+
+	err = strum.Unmarshal(line, &actual)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
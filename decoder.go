@@ -0,0 +1,103 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Decoder reads and decodes lines from an input stream.
+type Decoder struct {
+	r    *bufio.Reader
+	opts []Option
+	line int
+	next string
+	// ready is true when next holds a line that has not been decoded yet.
+	ready bool
+	err   error
+}
+
+// NewDecoder returns a new Decoder that reads from r. The given options are applied to every
+// call to Decode.
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	return &Decoder{
+		r:    bufio.NewReader(r),
+		opts: opts,
+	}
+}
+
+// More reports whether there is another line to decode.
+func (d *Decoder) More() bool {
+	d.peek()
+
+	return d.ready
+}
+
+// Decode reads the next line from its input and stores it in the value pointed to by v
+// (see Unmarshal). Lines may be of any length and may be terminated by either "\n" or "\r\n".
+//
+// Decode returns io.EOF when there are no more lines. Any other error indicates the number of
+// the line that failed, starting from 1.
+func (d *Decoder) Decode(v any) error {
+	line, text, err := d.read()
+	if err != nil {
+		return err
+	}
+
+	err = Unmarshal(text, v, d.opts...)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", line, err)
+	}
+
+	return nil
+}
+
+// read returns the next line and its number. It returns io.EOF when there are no more lines.
+func (d *Decoder) read() (int, string, error) {
+	d.peek()
+
+	if !d.ready {
+		if errors.Is(d.err, io.EOF) {
+			return d.line, "", io.EOF
+		}
+
+		return d.line + 1, "", fmt.Errorf("line %d: %w", d.line+1, d.err)
+	}
+
+	d.ready = false
+
+	return d.line, d.next, nil
+}
+
+func (d *Decoder) peek() {
+	if d.ready || d.err != nil {
+		return
+	}
+
+	line, err := d.r.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		d.err = err
+
+		return
+	}
+
+	d.line++
+	d.next = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+	d.ready = true
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+type decoderRecord struct {
+	Name   string `strum:"0,3"`
+	Amount int    `strum:"3,6"`
+}
+
+func TestDecoder(t *testing.T) { //nolint:funlen
+	t.Run("decodes every line", func(t *testing.T) {
+		d := strum.NewDecoder(strings.NewReader("abc001\ndef002\n"))
+
+		var records []decoderRecord
+
+		for d.More() {
+			var r decoderRecord
+
+			err := d.Decode(&r)
+			require.NoError(t, err)
+
+			records = append(records, r)
+		}
+
+		require.Equal(t, []decoderRecord{{"abc", 1}, {"def", 2}}, records)
+	})
+
+	t.Run("handles CRLF and a missing final line terminator", func(t *testing.T) {
+		d := strum.NewDecoder(strings.NewReader("abc001\r\ndef002"))
+
+		var r decoderRecord
+
+		require.NoError(t, d.Decode(&r))
+		require.Equal(t, decoderRecord{"abc", 1}, r)
+
+		require.NoError(t, d.Decode(&r))
+		require.Equal(t, decoderRecord{"def", 2}, r)
+
+		require.False(t, d.More())
+		require.ErrorIs(t, d.Decode(&r), io.EOF)
+	})
+
+	t.Run("handles long lines", func(t *testing.T) {
+		long := strings.Repeat("x", 1<<20)
+
+		d := strum.NewDecoder(strings.NewReader("abc001" + long + "\n"))
+
+		var r decoderRecord
+
+		require.NoError(t, d.Decode(&r))
+		require.Equal(t, decoderRecord{"abc", 1}, r)
+	})
+
+	t.Run("applies options", func(t *testing.T) {
+		d := strum.NewDecoder(
+			strings.NewReader("abcdef\n"),
+			strum.WithFormatter("upper", func(s string) (string, error) {
+				return strings.ToUpper(s), nil
+			}),
+		)
+
+		r := struct {
+			Val string `strform:"upper" strum:"0"`
+		}{}
+
+		require.NoError(t, d.Decode(&r))
+		require.Equal(t, "ABCDEF", r.Val)
+	})
+
+	t.Run("returns io.EOF on empty input", func(t *testing.T) {
+		d := strum.NewDecoder(strings.NewReader(""))
+
+		require.False(t, d.More())
+		require.ErrorIs(t, d.Decode(&decoderRecord{}), io.EOF)
+	})
+
+	t.Run("errors include the line number", func(t *testing.T) {
+		d := strum.NewDecoder(strings.NewReader("abc001\nabcxyz\n"))
+
+		var r decoderRecord
+
+		require.NoError(t, d.Decode(&r))
+
+		err := d.Decode(&r)
+		require.ErrorContains(t, err, "line 2: ")
+		require.ErrorContains(t, err, `field "Amount"`)
+	})
+
+	t.Run("read errors include the line number", func(t *testing.T) {
+		expected := errors.New("test")
+
+		d := strum.NewDecoder(io.MultiReader(
+			strings.NewReader("abc001\n"),
+			&errReader{err: expected},
+		))
+
+		var r decoderRecord
+
+		require.NoError(t, d.Decode(&r))
+
+		err := d.Decode(&r)
+		require.ErrorIs(t, err, expected)
+		require.ErrorContains(t, err, "line 2: ")
+	})
+}
+
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,83 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"bufio"
+	"io"
+)
+
+const (
+	// LF terminates records with a line feed. This is the default terminator.
+	LF = "\n"
+	// CRLF terminates records with a carriage return followed by a line feed.
+	CRLF = "\r\n"
+	// NoTerminator writes records back to back, as found in blocked files.
+	NoTerminator = ""
+)
+
+// WithTerminator makes Encoder end every record with the given terminator instead of LF.
+func WithTerminator(terminator string) Option {
+	return func(o *options) {
+		o.terminator = terminator
+	}
+}
+
+// Encoder encodes and writes records to an output stream.
+//
+// Writes are buffered: Flush must be called once all records are encoded.
+type Encoder struct {
+	w          *bufio.Writer
+	opts       []Option
+	terminator string
+}
+
+// NewEncoder returns a new Encoder that writes to w. The given options are applied to every call
+// to Encode.
+func NewEncoder(w io.Writer, opts ...Option) *Encoder {
+	options := *defaultOptions
+
+	for i := range opts {
+		opts[i](&options)
+	}
+
+	return &Encoder{
+		w:          bufio.NewWriter(w),
+		opts:       opts,
+		terminator: options.terminator,
+	}
+}
+
+// Encode writes the encoding of v (see Marshal) followed by the record terminator.
+func (e *Encoder) Encode(v any) error {
+	line, err := Marshal(v, e.opts...)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.WriteString(line)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.WriteString(e.terminator)
+
+	return err
+}
+
+// Flush writes any buffered records to the underlying io.Writer.
+func (e *Encoder) Flush() error {
+	return e.w.Flush()
+}
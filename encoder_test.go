@@ -0,0 +1,134 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+func TestEncoder(t *testing.T) { //nolint:funlen
+	records := []decoderRecord{{"abc", 1}, {"def", 2}}
+
+	t.Run("terminates records with LF by default", func(t *testing.T) {
+		var b strings.Builder
+
+		e := strum.NewEncoder(&b)
+
+		for i := range records {
+			require.NoError(t, e.Encode(records[i]))
+		}
+
+		require.NoError(t, e.Flush())
+		require.Equal(t, "abc001\ndef002\n", b.String())
+	})
+
+	t.Run("custom terminators", func(t *testing.T) {
+		tests := map[string]string{
+			strum.CRLF:         "abc001\r\ndef002\r\n",
+			strum.NoTerminator: "abc001def002",
+		}
+
+		for terminator, expected := range tests {
+			var b strings.Builder
+
+			e := strum.NewEncoder(&b, strum.WithTerminator(terminator))
+
+			for i := range records {
+				require.NoError(t, e.Encode(records[i]))
+			}
+
+			require.NoError(t, e.Flush())
+			require.Equal(t, expected, b.String())
+		}
+	})
+
+	t.Run("buffers records until flushed", func(t *testing.T) {
+		var b strings.Builder
+
+		e := strum.NewEncoder(&b)
+
+		require.NoError(t, e.Encode(records[0]))
+		require.Empty(t, b.String())
+
+		require.NoError(t, e.Flush())
+		require.Equal(t, "abc001\n", b.String())
+	})
+
+	t.Run("applies options", func(t *testing.T) {
+		var b strings.Builder
+
+		e := strum.NewEncoder(&b, strum.WithPadding(' '))
+
+		require.NoError(t, e.Encode(records[0]))
+		require.NoError(t, e.Flush())
+		require.Equal(t, "abc  1\n", b.String())
+	})
+
+	t.Run("output can be read by Decoder", func(t *testing.T) {
+		var b strings.Builder
+
+		e := strum.NewEncoder(&b, strum.WithTerminator(strum.CRLF))
+
+		for i := range records {
+			require.NoError(t, e.Encode(records[i]))
+		}
+
+		require.NoError(t, e.Flush())
+
+		d := strum.NewDecoder(strings.NewReader(b.String()))
+
+		var actual []decoderRecord
+
+		for d.More() {
+			var r decoderRecord
+
+			require.NoError(t, d.Decode(&r))
+
+			actual = append(actual, r)
+		}
+
+		require.Equal(t, records, actual)
+	})
+
+	t.Run("returns Marshal errors", func(t *testing.T) {
+		e := strum.NewEncoder(&strings.Builder{})
+
+		err := e.Encode(decoderRecord{Name: "abcdef"})
+		require.ErrorContains(t, err, "does not fit")
+	})
+
+	t.Run("returns write errors", func(t *testing.T) {
+		expected := errors.New("test")
+
+		e := strum.NewEncoder(&errWriter{err: expected})
+
+		require.NoError(t, e.Encode(records[0]))
+		require.ErrorIs(t, e.Flush(), expected)
+	})
+}
+
+type errWriter struct {
+	err error
+}
+
+func (w *errWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
@@ -0,0 +1,204 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotPointer is returned when Unmarshal is not given a pointer.
+	ErrNotPointer = errors.New("not a pointer")
+	// ErrNilPointer is returned when Unmarshal or Marshal is given a nil pointer.
+	ErrNilPointer = errors.New("nil pointer")
+	// ErrNotStruct is returned when the given value is not a struct or a pointer to one.
+	ErrNotStruct = errors.New("not a struct")
+	// ErrOutOfBounds is returned when a field, or one of its occurrences, is outside of the line.
+	ErrOutOfBounds = errors.New("out of bounds")
+	// ErrInvalidTag is returned when the tags of a field do not describe a valid layout.
+	ErrInvalidTag = errors.New("invalid tag")
+	// ErrUnknownFormatter is returned when a field's formatter was not registered with
+	// WithFormatter.
+	ErrUnknownFormatter = errors.New("unknown formatter")
+	// ErrFormatterFailed is returned when a field's formatter returns an error.
+	ErrFormatterFailed = errors.New("formatter failed")
+	// ErrParse is returned when a field's substring cannot be decoded into its type.
+	ErrParse = errors.New("cannot assign value")
+	// ErrInvalidLayout is returned by Validate when fields overlap, leave gaps or do not fit the
+	// record length.
+	ErrInvalidLayout = errors.New("invalid layout")
+	// ErrRecordLength is returned when a line does not have the declared record length (see
+	// WithStrictRecordLength).
+	ErrRecordLength = errors.New("invalid record length")
+)
+
+// WithAllErrors makes Unmarshal decode every field even after some of them fail, and return the
+// failures as FieldErrors instead of returning the first one.
+func WithAllErrors() Option {
+	return func(o *options) {
+		o.allErrors = true
+	}
+}
+
+// FieldError is the failure to decode a single field. Unmarshal returns every error that is
+// specific to a field as a *FieldError, whose cause may be matched against the sentinel errors
+// with errors.Is.
+type FieldError struct {
+	// Field is the name of the struct field.
+	Field string
+	// Path locates the field from the outermost struct, e.g. "Address.Street" or "Fees[2]".
+	Path string
+	// StartIdx is the start index of the field in the line.
+	StartIdx int
+	// EndIdx is the end index of the field in the line, or -1 if it extends to the end of the
+	// line.
+	EndIdx int
+	// Value is the field's substring, before any transcoding or formatting.
+	Value string
+	// Err is the cause of the failure.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors are the failures of every field that could not be decoded, in the order of the
+// fields (see WithAllErrors).
+type FieldErrors []*FieldError
+
+// Error returns the messages of the errors, separated by newlines like errors.Join.
+func (e FieldErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = e[i].Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors so that errors.Is and errors.As inspect each of them.
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i := range e {
+		errs[i] = e[i]
+	}
+
+	return errs
+}
+
+// kindError classifies an error with one of the sentinel errors without changing its message.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// classify returns err classified with the sentinel error kind.
+func classify(kind, err error) error {
+	return &kindError{kind: kind, err: err}
+}
+
+// parseError returns the failure err to decode the substring s into the field f. The failures of
+// occurrences of repeated groups are named by decodeOccurrence instead.
+func (f *fieldPlan) parseError(s string, err error) error {
+	if f.element {
+		return classify(ErrParse, fmt.Errorf("cannot assign value %q: %w", s, err))
+	}
+
+	return classify(ErrParse, fmt.Errorf("cannot assign value %q to field %q: %w", s, f.name, err))
+}
+
+// fieldErrors returns the failure err of field f, whose substring is rawVal, as FieldErrors
+// relative to the enclosing struct. The failures of nested structs and repeated groups are
+// relocated under f.
+func (f *fieldPlan) fieldErrors(err error, rawVal string, path string) FieldErrors {
+	switch e := err.(type) {
+	case FieldErrors:
+		return e.relocate(path, f.startIdx)
+	case *FieldError:
+		return FieldErrors{e}.relocate(path, f.startIdx)
+	}
+
+	return FieldErrors{{
+		Field:    f.name,
+		Path:     path,
+		StartIdx: f.startIdx,
+		EndIdx:   f.endIdx,
+		Value:    rawVal,
+		Err:      err,
+	}}
+}
+
+// wrapError returns err wrapped like fmt.Errorf(format+": %w", args..., err). The causes of
+// FieldError and FieldErrors are wrapped instead, so that they remain the outermost errors.
+func wrapError(err error, format string, args ...any) error {
+	wrap := func(err error) error {
+		return fmt.Errorf(format+": %w", append(args, err)...)
+	}
+
+	wrapField := func(e *FieldError) *FieldError {
+		wrapped := *e
+		wrapped.Err = wrap(e.Err)
+
+		return &wrapped
+	}
+
+	switch e := err.(type) {
+	case *FieldError:
+		return wrapField(e)
+	case FieldErrors:
+		wrapped := make(FieldErrors, len(e))
+		for i := range e {
+			wrapped[i] = wrapField(e[i])
+		}
+
+		return wrapped
+	default:
+		return wrap(err)
+	}
+}
+
+// relocate prefixes the paths of the errors with path and shifts their indexes by offset.
+func (e FieldErrors) relocate(path string, offset int) FieldErrors {
+	for _, fe := range e {
+		if strings.HasPrefix(fe.Path, "[") {
+			fe.Path = path + fe.Path
+		} else {
+			fe.Path = path + "." + fe.Path
+		}
+
+		fe.StartIdx += offset
+
+		if fe.EndIdx != -1 {
+			fe.EndIdx += offset
+		}
+	}
+
+	return e
+}
@@ -0,0 +1,179 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+type triageRecord struct {
+	Name    string `strum:"0,3"`
+	Amount  int    `strum:"3,6"`
+	Address struct {
+		Zip  int    `strum:"0,2"`
+		City string `strum:"2,4"`
+	} `strum:"6,10"`
+	Fees  []int `strum:"10,16,occurs=3"`
+	Extra int   `strum:"16,30"`
+}
+
+func TestUnmarshal_allErrors(t *testing.T) { //nolint:funlen
+	t.Run("collects every failure", func(t *testing.T) {
+		test := &triageRecord{}
+
+		err := strum.Unmarshal("abcx12zzNY01qq03", test, strum.WithAllErrors())
+
+		var errs strum.FieldErrors
+		require.ErrorAs(t, err, &errs)
+
+		type failure struct {
+			Field    string
+			Path     string
+			StartIdx int
+			EndIdx   int
+			Value    string
+		}
+
+		failures := make([]failure, len(errs))
+		for i, fe := range errs {
+			failures[i] = failure{fe.Field, fe.Path, fe.StartIdx, fe.EndIdx, fe.Value}
+		}
+
+		require.Equal(t, []failure{
+			{"Amount", "Amount", 3, 6, "x12"},
+			{"Zip", "Address.Zip", 6, 8, "zz"},
+			{"Fees", "Fees[1]", 12, 14, "qq"},
+			{"Extra", "Extra", 16, 30, ""},
+		}, failures)
+
+		// the other fields are decoded
+		require.Equal(t, "abc", test.Name)
+		require.Equal(t, "NY", test.Address.City)
+		require.Equal(t, []int{1, 0, 3}, test.Fees)
+
+		require.ErrorIs(t, err, strconv.ErrSyntax)
+		require.ErrorContains(t, err, `cannot assign value "x12" to field "Amount"`)
+		require.ErrorContains(t, err, "\nfield \"Address\": cannot assign value \"zz\"")
+		require.ErrorContains(t, err, "\ninvalid indexes on field \"Extra\"")
+
+		var fe *strum.FieldError
+		require.ErrorAs(t, err, &fe)
+		require.Equal(t, "Amount", fe.Field)
+		require.ErrorIs(t, errors.Unwrap(fe), strconv.ErrSyntax)
+	})
+
+	t.Run("nil when every field is decoded", func(t *testing.T) {
+		test := &struct {
+			Val int `strum:"0,1"`
+		}{}
+
+		require.NoError(t, strum.Unmarshal("1", test, strum.WithAllErrors()))
+		require.Equal(t, 1, test.Val)
+	})
+
+	t.Run("stops at the first failure by default", func(t *testing.T) {
+		err := strum.Unmarshal("abcx12zzNY01qq03", &triageRecord{})
+		require.ErrorContains(t, err, `cannot assign value "x12" to field "Amount"`)
+		require.NotContains(t, err.Error(), "Zip")
+
+		var errs strum.FieldErrors
+		require.False(t, errors.As(err, &errs))
+	})
+}
+
+func TestUnmarshal_sentinelErrors(t *testing.T) { //nolint:funlen
+	var n int
+
+	type record struct {
+		Val int `strum:"0,2"`
+	}
+
+	tests := map[string]struct {
+		err  error
+		call func() error
+	}{
+		"not a pointer": {strum.ErrNotPointer, func() error {
+			return strum.Unmarshal("", record{})
+		}},
+		"nil pointer": {strum.ErrNilPointer, func() error {
+			return strum.Unmarshal("", (*record)(nil))
+		}},
+		"nil pointer to Marshal": {strum.ErrNilPointer, func() error {
+			_, err := strum.Marshal((*record)(nil))
+
+			return err
+		}},
+		"not a struct": {strum.ErrNotStruct, func() error {
+			return strum.Unmarshal("", &n)
+		}},
+		"out of bounds": {strum.ErrOutOfBounds, func() error {
+			return strum.Unmarshal("1", &record{})
+		}},
+		"invalid tag": {strum.ErrInvalidTag, func() error {
+			return strum.Unmarshal("", &struct {
+				Val int `strum:"x,2"`
+			}{})
+		}},
+		"end index before start index": {strum.ErrInvalidTag, func() error {
+			return strum.Unmarshal("123", &struct {
+				Val int `strum:"2,1"`
+			}{})
+		}},
+		"unknown formatter": {strum.ErrUnknownFormatter, func() error {
+			return strum.Unmarshal("12", &struct {
+				Val int `strform:"unknown" strum:"0,2"`
+			}{})
+		}},
+		"formatter failed": {strum.ErrFormatterFailed, func() error {
+			return strum.Unmarshal("12", &struct {
+				Val int `strform:"fail" strum:"0,2"`
+			}{}, strum.WithFormatter("fail", func(string) (string, error) {
+				return "", errors.New("test")
+			}))
+		}},
+		"parse": {strum.ErrParse, func() error {
+			return strum.Unmarshal("ab", &record{})
+		}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.call()
+			require.ErrorIs(t, err, test.err)
+		})
+	}
+
+	t.Run("field errors locate the field", func(t *testing.T) {
+		err := strum.Unmarshal("abc123zzNY", &triageRecord{})
+
+		var fe *strum.FieldError
+		require.ErrorAs(t, err, &fe)
+		require.Equal(t, "Zip", fe.Field)
+		require.Equal(t, "Address.Zip", fe.Path)
+		require.Equal(t, 6, fe.StartIdx)
+		require.Equal(t, 8, fe.EndIdx)
+		require.Equal(t, "zz", fe.Value)
+		require.ErrorIs(t, fe, strum.ErrParse)
+		require.ErrorIs(t, fe, strconv.ErrSyntax)
+		require.EqualError(t, err, `field "Address": cannot assign value "zz" to field "Zip": `+
+			`strconv.Atoi: parsing "zz": invalid syntax`)
+	})
+}
@@ -16,6 +16,7 @@ package strum_test
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/terminalstream/strum"
 )
@@ -87,3 +88,85 @@ func ExampleUnmarshal_formatter() {
 
 	// Output: 123
 }
+
+func ExampleMarshal() {
+	contact := struct {
+		FirstName    string `strum:"0,5"`
+		LastName     string `strum:"5,10"`
+		StreetNumber int    `strum:"10,15"`
+		Verified     bool   `strum:"15,19"`
+	}{
+		FirstName:    "Bob",
+		LastName:     "Dole",
+		StreetNumber: 123,
+		Verified:     true,
+	}
+
+	line, err := strum.Marshal(contact)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%q\n", line)
+
+	// Output: "Bob  Dole 00123true"
+}
+
+func ExampleDecoder() {
+	const data = "Bob  00123\nAlice00456\n"
+
+	type account struct {
+		Name    string `strum:"0,5"`
+		Balance int    `strum:"5,10"`
+	}
+
+	d := strum.NewDecoder(strings.NewReader(data))
+
+	for d.More() {
+		var a account
+
+		err := d.Decode(&a)
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Printf("%q %d\n", a.Name, a.Balance)
+	}
+
+	// Output:
+	// "Bob  " 123
+	// "Alice" 456
+}
+
+func ExampleRecordSet() {
+	type header struct {
+		Date string `strum:"2,10"`
+	}
+
+	type detail struct {
+		Amount int `strum:"2,7"`
+	}
+
+	rs := strum.NewRecordSet(0, 2).
+		Register("01", header{}).
+		Register("02", detail{})
+
+	for _, line := range []string{"0120240101", "0200123", "0200456"} {
+		record, err := rs.Unmarshal(line)
+		if err != nil {
+			panic(err)
+		}
+
+		switch r := record.(type) {
+		case header:
+			fmt.Println("header", r.Date)
+		case detail:
+			fmt.Println("detail", r.Amount)
+		}
+	}
+
+	// Output:
+	// header 20240101
+	// detail 123
+	// detail 456
+}
@@ -0,0 +1,213 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// indexUnit is the unit of the indexes of TagName.
+type indexUnit int
+
+const (
+	byteUnit indexUnit = iota
+	runeUnit
+	columnUnit
+)
+
+// WithRuneIndexes interprets the indexes of TagName as numbers of runes instead of bytes.
+//
+// It has no effect along with WithCodePage, whose characters are all one byte wide.
+func WithRuneIndexes() Option {
+	return func(o *options) {
+		o.unit = runeUnit
+	}
+}
+
+// WithDisplayWidthIndexes interprets the indexes of TagName as terminal columns instead of bytes:
+// East Asian wide and fullwidth characters occupy two columns, combining marks and format
+// characters none, and every other character one. An index that falls in the middle of a wide
+// character is an error.
+//
+// It has no effect along with WithCodePage, whose characters are all one byte wide.
+func WithDisplayWidthIndexes() Option {
+	return func(o *options) {
+		o.unit = columnUnit
+	}
+}
+
+// WithOneBasedIndexes interprets the start indexes of TagName, and of NewRecordSet, as 1-based
+// positions and their end indexes as inclusive. Indexes of repeated groups' occurrences and of
+// nested structs remain relative to the start of their field, whose first position is 1.
+func WithOneBasedIndexes() Option {
+	return func(o *options) {
+		o.oneBased = true
+	}
+}
+
+// indexUnit returns the unit of the indexes of TagName.
+func (o *options) indexUnit() indexUnit {
+	if o.codePage != nil {
+		return byteUnit
+	}
+
+	return o.unit
+}
+
+func (u indexUnit) String() string {
+	switch u {
+	case runeUnit:
+		return "runes"
+	case columnUnit:
+		return "columns"
+	default:
+		return "bytes"
+	}
+}
+
+// measure returns the length of s in unit u.
+func (u indexUnit) measure(s string) int {
+	switch u {
+	case runeUnit:
+		return utf8.RuneCountInString(s)
+	case columnUnit:
+		n := 0
+		for _, r := range s {
+			n += runeWidth(r)
+		}
+
+		return n
+	default:
+		return len(s)
+	}
+}
+
+// offset returns the byte offset of index idx of s, which must be within the bounds of s. It
+// returns false if idx falls in the middle of a character. Characters of zero width belong to the
+// preceding character.
+func (u indexUnit) offset(s string, idx int) (int, bool) {
+	if u == byteUnit {
+		return idx, true
+	}
+
+	n := 0
+
+	for i, r := range s {
+		w := 1
+		if u == columnUnit {
+			w = runeWidth(r)
+		}
+
+		if n+w > idx {
+			return i, n == idx
+		}
+
+		n += w
+	}
+
+	return len(s), n == idx
+}
+
+// slice returns the substring of s between startIdx and endIdx, or to the end of s if endIdx is
+// -1.
+func (u indexUnit) slice(s string, startIdx, endIdx int) (string, error) {
+	if u == byteUnit {
+		if endIdx == -1 {
+			endIdx = len(s)
+		}
+
+		err := validateIndexes(len(s), u, startIdx, endIdx)
+		if err != nil {
+			return "", err
+		}
+
+		return s[startIdx:endIdx], nil
+	}
+
+	length := u.measure(s)
+	if endIdx == -1 {
+		endIdx = length
+	}
+
+	err := validateIndexes(length, u, startIdx, endIdx)
+	if err != nil {
+		return "", err
+	}
+
+	start, end, err := u.offsets(s, startIdx, endIdx)
+	if err != nil {
+		return "", err
+	}
+
+	return s[start:end], nil
+}
+
+// offsets returns the byte offsets of startIdx and endIdx in s.
+func (u indexUnit) offsets(s string, startIdx, endIdx int) (int, int, error) {
+	start, ok := u.offset(s, startIdx)
+	if !ok {
+		return 0, 0, classify(
+			ErrOutOfBounds, fmt.Errorf("start index %d splits a wide character", startIdx),
+		)
+	}
+
+	end, ok := u.offset(s, endIdx)
+	if !ok {
+		return 0, 0, classify(
+			ErrOutOfBounds, fmt.Errorf("end index %d splits a wide character", endIdx),
+		)
+	}
+
+	return start, end, nil
+}
+
+// wide are the East Asian wide and fullwidth characters.
+var wide = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x1100, Hi: 0x115f, Stride: 1},
+		{Lo: 0x2e80, Hi: 0x303e, Stride: 1},
+		{Lo: 0x3041, Hi: 0x33ff, Stride: 1},
+		{Lo: 0x3400, Hi: 0x4dbf, Stride: 1},
+		{Lo: 0x4e00, Hi: 0x9fff, Stride: 1},
+		{Lo: 0xa000, Hi: 0xa4cf, Stride: 1},
+		{Lo: 0xa960, Hi: 0xa97f, Stride: 1},
+		{Lo: 0xac00, Hi: 0xd7a3, Stride: 1},
+		{Lo: 0xf900, Hi: 0xfaff, Stride: 1},
+		{Lo: 0xfe10, Hi: 0xfe19, Stride: 1},
+		{Lo: 0xfe30, Hi: 0xfe6f, Stride: 1},
+		{Lo: 0xff00, Hi: 0xff60, Stride: 1},
+		{Lo: 0xffe0, Hi: 0xffe6, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f300, Hi: 0x1f64f, Stride: 1},
+		{Lo: 0x1f900, Hi: 0x1f9ff, Stride: 1},
+		{Lo: 0x20000, Hi: 0x2fffd, Stride: 1},
+		{Lo: 0x30000, Hi: 0x3fffd, Stride: 1},
+	},
+}
+
+// runeWidth returns the number of terminal columns occupied by r.
+func runeWidth(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case unicode.Is(wide, r):
+		return 2
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+type unicodeRecord struct {
+	Name  string   `strum:"0,6"`
+	Codes []string `strum:"6,10,occurs=2"`
+	City  string   `strum:"10"`
+}
+
+func TestUnmarshal_runeIndexes(t *testing.T) {
+	t.Run("indexes count runes", func(t *testing.T) {
+		test := &unicodeRecord{}
+
+		err := strum.Unmarshal("José  éaüoZürich", test, strum.WithRuneIndexes())
+		require.NoError(t, err)
+		require.Equal(t, unicodeRecord{"José  ", []string{"éa", "üo"}, "Zürich"}, *test)
+	})
+
+	t.Run("error reports bounds in runes", func(t *testing.T) {
+		test := &struct {
+			Val string `strum:"0,5"`
+		}{}
+
+		err := strum.Unmarshal("José", test, strum.WithRuneIndexes())
+		require.ErrorContains(t, err, "end index out of bounds: 5 not within 4 runes")
+	})
+
+	t.Run("discriminators of record sets", func(t *testing.T) {
+		rs := strum.NewRecordSet(1, 2, strum.WithRuneIndexes()).
+			Register("B", struct{}{})
+
+		v, err := rs.Unmarshal("éB")
+		require.NoError(t, err)
+		require.Equal(t, struct{}{}, v)
+	})
+}
+
+func TestUnmarshal_displayWidthIndexes(t *testing.T) {
+	t.Run("wide characters occupy two columns", func(t *testing.T) {
+		test := &struct {
+			Name string `strum:"0,6"`
+			City string `strum:"6,10"`
+			Note string `strum:"10"`
+		}{}
+
+		err := strum.Unmarshal("山田太東京éx", test, strum.WithDisplayWidthIndexes())
+		require.NoError(t, err)
+		require.Equal(t, "山田太", test.Name)
+		require.Equal(t, "東京", test.City)
+		require.Equal(t, "éx", test.Note)
+	})
+
+	t.Run("error when an index splits a wide character", func(t *testing.T) {
+		test := &struct {
+			Val string `strum:"1,4"`
+		}{}
+
+		err := strum.Unmarshal("山田", test, strum.WithDisplayWidthIndexes())
+		require.ErrorContains(t, err, "start index 1 splits a wide character")
+	})
+
+	t.Run("error reports bounds in columns", func(t *testing.T) {
+		test := &struct {
+			Val string `strum:"5"`
+		}{}
+
+		err := strum.Unmarshal("山田", test, strum.WithDisplayWidthIndexes())
+		require.ErrorContains(t, err, "start index out of bounds: 5 not within 4 columns")
+	})
+}
+
+func TestMarshal_runeIndexes(t *testing.T) {
+	t.Run("widths count runes", func(t *testing.T) {
+		line, err := strum.Marshal(
+			unicodeRecord{"José", []string{"é", "üo"}, "Zürich"}, strum.WithRuneIndexes(),
+		)
+		require.NoError(t, err)
+		require.Equal(t, "José  é üoZürich", line)
+	})
+
+	t.Run("widths count columns", func(t *testing.T) {
+		line, err := strum.Marshal(&struct {
+			City string `strum:"4,10"`
+			Name string `strum:"0,3"`
+		}{"東京", "山"}, strum.WithDisplayWidthIndexes())
+		require.NoError(t, err)
+		require.Equal(t, "山  東京  ", line)
+	})
+
+	t.Run("error when a value does not fit", func(t *testing.T) {
+		_, err := strum.Marshal(&struct {
+			Name string `strum:"0,3"`
+		}{"山田"}, strum.WithDisplayWidthIndexes())
+		require.ErrorContains(t, err, `value "山田" does not fit field "Name" of width 3`)
+	})
+}
+
+func TestUnmarshal_positions(t *testing.T) { //nolint:funlen
+	type record struct {
+		Code  string `strum:"pos=0,len=2"`
+		Name  string `strum:"pos=2,len=4"`
+		Other string `strum:"pos=6"`
+	}
+
+	t.Run("start and length", func(t *testing.T) {
+		test := &record{}
+
+		err := strum.Unmarshal("ABJohnRest", test)
+		require.NoError(t, err)
+		require.Equal(t, record{"AB", "John", "Rest"}, *test)
+	})
+
+	t.Run("1-based inclusive indexes", func(t *testing.T) {
+		test := &struct {
+			Code string `strum:"1,2"`
+			Name string `strum:"pos=3,len=4"`
+			Rest string `strum:"7"`
+			All  string `strum:",4"`
+		}{}
+
+		err := strum.Unmarshal("ABJohnRest", test, strum.WithOneBasedIndexes())
+		require.NoError(t, err)
+		require.Equal(t, "AB", test.Code)
+		require.Equal(t, "John", test.Name)
+		require.Equal(t, "Rest", test.Rest)
+		require.Equal(t, "ABJo", test.All)
+
+		line, err := strum.Marshal(test, strum.WithOneBasedIndexes())
+		require.NoError(t, err)
+		require.Equal(t, "ABJohnRest", line)
+	})
+
+	t.Run("1-based discriminators of record sets", func(t *testing.T) {
+		rs := strum.NewRecordSet(2, 2, strum.WithOneBasedIndexes()).
+			Register("B", struct{}{})
+
+		v, err := rs.Unmarshal("AB")
+		require.NoError(t, err)
+		require.Equal(t, struct{}{}, v)
+	})
+
+	t.Run("errors point at the tag", func(t *testing.T) {
+		tests := map[string]any{
+			`tagged strum:"1,pos=2": option "pos" cannot be combined with indexes`: &struct {
+				Val string `strum:"1,pos=2"`
+			}{},
+			`tagged strum:"len=2": option "len" requires option "pos"`: &struct {
+				Val string `strum:"len=2"`
+			}{},
+			`tagged strum:"pos=x": invalid "pos" option "x"`: &struct {
+				Val string `strum:"pos=x"`
+			}{},
+			`tagged strum:"pos=1,len=-1": invalid "len" option "-1"`: &struct {
+				Val string `strum:"pos=1,len=-1"`
+			}{},
+			`tagged strum:"0,2": start index 0 must be at least 1`: &struct {
+				Val string `strum:"0,2"`
+			}{},
+			`tagged strum:"3,2": end index must be greater or equal to start index: 2 < 3`: &struct {
+				Val string `strum:"3,2"`
+			}{},
+			`tagged strum:"1,9": end index out of bounds`: &struct {
+				Val string `strum:"1,9"`
+			}{},
+		}
+
+		for expected, test := range tests {
+			t.Run(expected, func(t *testing.T) {
+				err := strum.Unmarshal("abc", test, strum.WithOneBasedIndexes())
+				require.ErrorContains(t, err, expected)
+			})
+		}
+	})
+}
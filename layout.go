@@ -0,0 +1,72 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Fields returns the layout of v, which must be a struct or a pointer to one, possibly nil: the
+// fields processed by Unmarshal and Marshal, in declaration order, with their absolute indexes.
+// This is how the offsets computed from the "w" and "skip" options can be inspected.
+//
+// The fields of nested structs follow their parent field and are named after their path, e.g.
+// "Address.Street". Repeated groups are reported as a single field.
+func Fields(v any, opts ...Option) ([]Field, error) {
+	options := *defaultOptions
+
+	for i := range opts {
+		opts[i](&options)
+	}
+
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %v", ErrNotStruct, t)
+	}
+
+	p, err := compile(t, &options)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.layout(nil, "", 0), nil
+}
+
+// layout appends the fields of p to fields, prefixing their names with prefix and shifting their
+// indexes by offset.
+func (p *plan) layout(fields []Field, prefix string, offset int) []Field {
+	for i := range p.fields {
+		f := p.fields[i].field
+		f.Name = prefix + f.Name
+		f.StartIdx += offset
+
+		if f.EndIdx != -1 {
+			f.EndIdx += offset
+		}
+
+		fields = append(fields, f)
+
+		if nested := p.fields[i].nested; nested != nil {
+			fields = nested.layout(fields, f.Name+".", f.StartIdx)
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,133 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+type sequentialAddress struct {
+	Street string `strum:"w=4"`
+	_      string `strum:"w=1"`
+	City   string `strum:"w=3"`
+}
+
+type sequentialRecord struct {
+	Code    string            `strum:"w=2"`
+	_       struct{}          `strum:"skip=1"`
+	Name    string            `strum:"w=4"`
+	Amount  int               `strum:"skip=1,w=3"`
+	Address sequentialAddress `strum:"w=8"`
+	Fixed   string            `strum:"30,32"`
+	Rest    string            `strum:"w=2"`
+}
+
+const sequentialLine = "AB-John/042MainXNYC" + "           " + "XY" + "ZZ"
+
+func TestUnmarshal_sequential(t *testing.T) {
+	t.Run("offsets are cumulative", func(t *testing.T) {
+		test := &sequentialRecord{}
+
+		err := strum.Unmarshal(sequentialLine, test)
+		require.NoError(t, err)
+		require.Equal(t, sequentialRecord{
+			Code:    "AB",
+			Name:    "John",
+			Amount:  42,
+			Address: sequentialAddress{Street: "Main", City: "NYC"},
+			Fixed:   "XY",
+			Rest:    "ZZ",
+		}, *test)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := map[string]any{
+			`options "w" and "skip" cannot be combined with indexes`: &struct {
+				Val string `strum:"0,w=2"`
+			}{},
+			`options "w" and "skip" cannot follow a field that extends to the end`: &struct {
+				A string `strum:"0"`
+				B string `strum:"w=2"`
+			}{},
+			`invalid "w" option "-1"`: &struct {
+				Val string `strum:"w=-1"`
+			}{},
+		}
+
+		for expected, test := range tests {
+			t.Run(expected, func(t *testing.T) {
+				err := strum.Unmarshal("abc", test)
+				require.ErrorContains(t, err, expected)
+			})
+		}
+	})
+}
+
+func TestMarshal_sequential(t *testing.T) {
+	line, err := strum.Marshal(sequentialRecord{
+		Code:    "AB",
+		Name:    "John",
+		Amount:  42,
+		Address: sequentialAddress{Street: "Main", City: "NYC"},
+		Fixed:   "XY",
+		Rest:    "ZZ",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "AB John 042Main NYC           XYZZ", line)
+}
+
+func TestFields(t *testing.T) {
+	t.Run("absolute offsets", func(t *testing.T) {
+		fields, err := strum.Fields((*sequentialRecord)(nil))
+		require.NoError(t, err)
+
+		type span struct {
+			Name     string
+			StartIdx int
+			EndIdx   int
+		}
+
+		spans := make([]span, len(fields))
+		for i, f := range fields {
+			spans[i] = span{f.Name, f.StartIdx, f.EndIdx}
+		}
+
+		require.Equal(t, []span{
+			{"Code", 0, 2},
+			{"Name", 3, 7},
+			{"Amount", 8, 11},
+			{"Address", 11, 19},
+			{"Address.Street", 11, 15},
+			{"Address.City", 16, 19},
+			{"Fixed", 30, 32},
+			{"Rest", 32, 34},
+		}, spans)
+
+		require.Equal(t, reflect.StructTag(`strum:"w=2"`), fields[0].Tag)
+	})
+
+	t.Run("error when not a struct", func(t *testing.T) {
+		_, err := strum.Fields(1)
+		require.ErrorContains(t, err, "not a struct: int")
+
+		_, err = strum.Fields(nil)
+		require.ErrorContains(t, err, "not a struct")
+	})
+}
@@ -0,0 +1,349 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Justification determines on which side of its field Marshal places a value that is
+// narrower than the field.
+type Justification int
+
+const (
+	// JustifyDefault right-justifies numbers and left-justifies everything else.
+	JustifyDefault Justification = iota
+	// JustifyLeft places values at the start of their fields.
+	JustifyLeft
+	// JustifyRight places values at the end of their fields.
+	JustifyRight
+)
+
+const (
+	defaultPadding        = ' '
+	defaultNumericPadding = '0'
+)
+
+// WithPadding fills the unused portion of every field with the given byte. By default numbers
+// are padded with '0' and everything else with ' '.
+func WithPadding(padding byte) Option {
+	return func(o *options) {
+		o.padding = padding
+	}
+}
+
+// WithJustification aligns every field according to the given Justification instead of
+// JustifyDefault.
+func WithJustification(j Justification) Option {
+	return func(o *options) {
+		o.justification = j
+	}
+}
+
+// Marshal encodes structs into strings. It is the inverse of Unmarshal.
+//
+// v must be a struct or a non-nil pointer to one. Every field tagged with TagName whose type
+// is supported by Unmarshal is written into the slot indicated by its indexes (see Unmarshal).
+// Values narrower than their slot are padded (see WithPadding and WithJustification) and values
+// wider than their slot raise an error. A field with just a startIdx is written with its natural
+// width. Gaps between fields are filled with spaces, as are fillers and blank fields, and lines
+// are padded to the record length declared with the "reclen" option or WithRecordLength, if any
+// (see Validate). Nil pointers are written as blanks, which Unmarshal decodes as nil pointers
+// except for pointers to strings, which need the "omitempty" flag.
+//
+// Nested structs are encoded recursively into their slot. Fields whose type implements
+// FieldMarshaler or encoding.TextMarshaler encode themselves. Times are
+// formatted in the location given by WithLocation, and zero times are written as empty values.
+//
+// Lines are encoded in UTF-8 unless a code page is selected with WithCodePage, in which case
+// widths are measured after transcoding.
+//
+// Formatters only apply to Unmarshal and are ignored by Marshal.
+func Marshal(v any, opts ...Option) (string, error) {
+	options := *defaultOptions
+
+	for i := range opts {
+		opts[i](&options)
+	}
+
+	err := validateCodePage(&options)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := marshalInput(v)
+	if err != nil {
+		return "", err
+	}
+
+	p, err := compile(value.Type(), &options)
+	if err != nil {
+		return "", err
+	}
+
+	return p.encode(value, options.recordLength, &options)
+}
+
+// marshalInput returns the addressable struct value of v, a struct or a non-nil pointer to one.
+func marshalInput(v any) (reflect.Value, error) {
+	value := reflect.ValueOf(v)
+
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return reflect.Value{}, ErrNilPointer
+		}
+
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("%w: %s", ErrNotStruct, value.Kind())
+	}
+
+	if !value.CanAddr() {
+		// methods with pointer receivers require an addressable value
+		addressable := reflect.New(value.Type()).Elem()
+		addressable.Set(value)
+		value = addressable
+	}
+
+	return value, nil
+}
+
+// encode encodes value, a struct of the plan's type, into a line that spans every field and
+// filler and is padded to the record length, either length or the one declared by the plan.
+func (p *plan) encode(value reflect.Value, length int, o *options) (string, error) {
+	if length == 0 {
+		length = p.recordLength
+	}
+
+	length = max(length, p.width)
+	line := make([]byte, 0, length)
+
+	for i := range p.fields {
+		f := &p.fields[i]
+
+		strVal, err := f.encodeSlot(value, o)
+		if err != nil {
+			return "", err
+		}
+
+		line = place(line, f.startIdx, strVal, o)
+	}
+
+	return string(place(line, length, "", o)), nil
+}
+
+// encodeSlot encodes the field f of struct value, padded to the width of the field.
+func (f *fieldPlan) encodeSlot(value reflect.Value, o *options) (string, error) {
+	var (
+		strVal string
+		err    error
+	)
+
+	if f.elem != nil {
+		strVal, err = f.encodeOccurs(value, o)
+	} else {
+		strVal, err = f.encodeValue(value.Field(f.index), f.width(), o)
+	}
+
+	if err != nil || f.endIdx == -1 {
+		return strVal, err
+	}
+
+	width := f.endIdx - f.startIdx
+	if o.indexUnit().measure(strVal) > width {
+		return "", fmt.Errorf("value %q does not fit field %q of width %d", strVal, f.name, width)
+	}
+
+	// empty values, eg. nil pointers, are left blank rather than written as zeros
+	return pad(strVal, width, f.numeric && strVal != "", o), nil
+}
+
+// encodeValue encodes fv, packs it to width, or -1 if unbounded, and transcodes it. Zero values
+// of fields with the "omitempty" flag or the "nullif" option are encoded as blanks or as the
+// "nullif" value.
+func (f *fieldPlan) encodeValue(fv reflect.Value, width int, o *options) (string, error) {
+	if f.encodesBlank(fv) {
+		return f.encodeBlank(width, o)
+	}
+
+	strVal, err := f.encode(fv, o)
+	if err != nil {
+		return "", err
+	}
+
+	strVal, err = f.pack(strVal, width)
+	if err != nil {
+		return "", fmt.Errorf("cannot encode field %q: %w", f.name, err)
+	}
+
+	return f.toCodePage(strVal, o)
+}
+
+// width returns the width of the field, or -1 if it extends to the end of the line.
+func (f *fieldPlan) width() int {
+	if f.endIdx == -1 {
+		return -1
+	}
+
+	return f.endIdx - f.startIdx
+}
+
+func (f *fieldPlan) encode(fv reflect.Value, o *options) (string, error) {
+	switch {
+	case f.timeLayout != "":
+		return encodeTime(fv, f.timeLayout, o), nil
+	case f.marshaler:
+		strVal, err := f.marshal(fv)
+		if err != nil {
+			return "", fmt.Errorf("cannot encode field %q: %w", f.name, err)
+		}
+
+		return strVal, nil
+	case f.nested == nil && f.encoder == nil:
+		return "", fmt.Errorf(
+			"cannot encode field %q: %s implements neither FieldMarshaler nor encoding.TextMarshaler",
+			f.name, fv.Type(),
+		)
+	case f.nested == nil:
+		return f.encoder(fv), nil
+	default:
+		return f.encodeNested(fv, o)
+	}
+}
+
+// encodeNested encodes the nested struct field f, leaving nil pointers blank.
+func (f *fieldPlan) encodeNested(fv reflect.Value, o *options) (string, error) {
+	if f.ptr {
+		if fv.IsNil() {
+			return "", nil
+		}
+
+		fv = fv.Elem()
+	}
+
+	strVal, err := f.nested.encode(fv, 0, o)
+	if err != nil {
+		return "", fmt.Errorf("field %q: %w", f.name, err)
+	}
+
+	return strVal, nil
+}
+
+// fieldEncoder returns the encoder for fields of type t and whether t is numeric.
+func fieldEncoder(t reflect.Type) (primitiveEncoder, bool, bool) {
+	switch t.Kind() { //nolint:exhaustive
+	case reflect.Ptr:
+		encoder, ok := builtinEncoders[t.Elem().Kind()]
+		if !ok {
+			return nil, false, false
+		}
+
+		return ptrEncoder(encoder), isNumeric(t.Elem().Kind()), true
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.Uint8 {
+			return nil, false, false
+		}
+
+		return func(v reflect.Value) string {
+			return string(v.Bytes())
+		}, false, true
+	case reflect.Array:
+		return byteArrayEncoder(t)
+	default:
+		encoder, ok := builtinEncoders[t.Kind()]
+
+		return encoder, isNumeric(t.Kind()), ok
+	}
+}
+
+func pad(s string, width int, numeric bool, o *options) string {
+	n := o.indexUnit().measure(s)
+	if n >= width {
+		return s
+	}
+
+	padding, justification := o.paddingOf(numeric)
+	filler := strings.Repeat(string([]byte{codePageByte(padding, o)}), width-n)
+
+	if justification == JustifyLeft {
+		return s + filler
+	}
+
+	// keep the sign in front of zero padding so that the result is still a valid number
+	if numeric && padding == '0' && hasSign(s, o) {
+		return s[:1] + filler + s[1:]
+	}
+
+	return filler + s
+}
+
+// paddingOf returns the padding byte and the justification of numeric or other values.
+func (o *options) paddingOf(numeric bool) (byte, Justification) {
+	padding, justification := o.padding, o.justification
+
+	if padding == 0 {
+		padding = defaultPadding
+		if numeric {
+			padding = defaultNumericPadding
+		}
+	}
+
+	if justification == JustifyDefault {
+		justification = JustifyLeft
+		if numeric {
+			justification = JustifyRight
+		}
+	}
+
+	return padding, justification
+}
+
+// hasSign returns true if s, encoded in the code page of o, starts with a sign.
+func hasSign(s string, o *options) bool {
+	return s != "" && (s[0] == codePageByte('-', o) || s[0] == codePageByte('+', o))
+}
+
+// place writes s into line starting at idx, growing line with spaces if necessary.
+func place(line []byte, idx int, s string, o *options) []byte {
+	u := o.indexUnit()
+	if u == byteUnit {
+		for len(line) < idx+len(s) {
+			line = append(line, codePageByte(defaultPadding, o))
+		}
+
+		copy(line[idx:], s)
+
+		return line
+	}
+
+	width := u.measure(s)
+
+	for length := u.measure(string(line)); length < idx+width; length++ {
+		line = append(line, defaultPadding)
+	}
+
+	start, _ := u.offset(string(line), idx)
+	end, _ := u.offset(string(line), idx+width)
+
+	placed := make([]byte, 0, len(line)-(end-start)+len(s))
+	placed = append(placed, line[:start]...)
+	placed = append(placed, s...)
+
+	return append(placed, line[end:]...)
+}
@@ -0,0 +1,365 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+func TestMarshal(t *testing.T) { //nolint:funlen
+	t.Run("writes fields into their slots", func(t *testing.T) {
+		test := struct {
+			First  string `strum:"0,5"`
+			Second int    `strum:"5,10"`
+			Third  bool   `strum:"10,14"`
+		}{
+			First:  "abc",
+			Second: 42,
+			Third:  true,
+		}
+
+		line, err := strum.Marshal(test)
+		require.NoError(t, err)
+		require.Equal(t, "abc  00042true", line)
+	})
+
+	t.Run("accepts pointers to structs", func(t *testing.T) {
+		test := &struct {
+			Val string `strum:"0,3"`
+		}{Val: "abc"}
+
+		line, err := strum.Marshal(test)
+		require.NoError(t, err)
+		require.Equal(t, "abc", line)
+	})
+
+	t.Run("writes suffix with just startIdx", func(t *testing.T) {
+		test := struct {
+			First  string `strum:",2"`
+			Second string `strum:"2"`
+		}{
+			First:  "ab",
+			Second: "cdefg",
+		}
+
+		line, err := strum.Marshal(test)
+		require.NoError(t, err)
+		require.Equal(t, "abcdefg", line)
+	})
+
+	t.Run("fills gaps with spaces", func(t *testing.T) {
+		test := struct {
+			First  string `strum:"0,1"`
+			Second string `strum:"3,4"`
+		}{
+			First:  "a",
+			Second: "b",
+		}
+
+		line, err := strum.Marshal(test)
+		require.NoError(t, err)
+		require.Equal(t, "a  b", line)
+	})
+
+	t.Run("keeps the sign in front of zero padding", func(t *testing.T) {
+		test := struct {
+			Val int `strum:"0,5"`
+		}{Val: -12}
+
+		line, err := strum.Marshal(test)
+		require.NoError(t, err)
+		require.Equal(t, "-0012", line)
+	})
+
+	t.Run("writes nil pointers as empty values", func(t *testing.T) {
+		test := struct {
+			Val *string `strum:"0,3"`
+		}{}
+
+		line, err := strum.Marshal(test)
+		require.NoError(t, err)
+		require.Equal(t, "   ", line)
+	})
+
+	t.Run("ignores fields without struct tag", func(t *testing.T) {
+		test := struct {
+			Val string `json:"val"`
+		}{Val: "abc"}
+
+		line, err := strum.Marshal(test)
+		require.NoError(t, err)
+		require.Empty(t, line)
+	})
+
+	t.Run("error when value does not fit its field", func(t *testing.T) {
+		test := struct {
+			Val string `strum:"0,2"`
+		}{Val: "abc"}
+
+		_, err := strum.Marshal(test)
+		require.ErrorContains(t, err, "does not fit")
+	})
+
+	t.Run("error when given a nil pointer", func(t *testing.T) {
+		var p *struct{}
+
+		_, err := strum.Marshal(p)
+		require.ErrorContains(t, err, "nil pointer")
+	})
+
+	t.Run("error when given something other than a struct", func(t *testing.T) {
+		_, err := strum.Marshal("abc")
+		require.ErrorContains(t, err, "not a struct")
+	})
+
+	t.Run("error when tag value is invalid", func(t *testing.T) {
+		test := struct {
+			Val string `strum:"invalid"`
+		}{}
+
+		_, err := strum.Marshal(test)
+		require.ErrorContains(t, err, "invalid start index")
+	})
+
+	t.Run("error when startIdx > endIdx", func(t *testing.T) {
+		test := struct {
+			Val string `strum:"2,1"`
+		}{}
+
+		_, err := strum.Marshal(test)
+		require.ErrorContains(t, err, "end index must be greater or equal to start index")
+	})
+}
+
+func TestMarshal_padding(t *testing.T) {
+	test := struct {
+		First  string `strum:"0,5"`
+		Second int    `strum:"5,10"`
+	}{
+		First:  "abc",
+		Second: 42,
+	}
+
+	t.Run("custom padding", func(t *testing.T) {
+		line, err := strum.Marshal(test, strum.WithPadding('*'))
+		require.NoError(t, err)
+		require.Equal(t, "abc*****42", line)
+	})
+
+	t.Run("left justification", func(t *testing.T) {
+		line, err := strum.Marshal(test, strum.WithJustification(strum.JustifyLeft))
+		require.NoError(t, err)
+		require.Equal(t, "abc  42000", line)
+	})
+
+	t.Run("right justification", func(t *testing.T) {
+		line, err := strum.Marshal(test, strum.WithJustification(strum.JustifyRight))
+		require.NoError(t, err)
+		require.Equal(t, "  abc00042", line)
+	})
+}
+
+func TestMarshal_roundTrip(t *testing.T) {
+	one := 1
+	text := "abc"
+
+	type record struct {
+		Bool    bool    `strum:"0,4"`
+		Int     int     `strum:"4,8"`
+		Int8    int8    `strum:"8,12"`
+		Int64   int64   `strum:"12,20"`
+		Uint    uint    `strum:"20,24"`
+		Uint16  uint16  `strum:"24,28"`
+		Float32 float32 `strum:"28,34"`
+		Float64 float64 `strum:"34,40"`
+		String  string  `strum:"40,43"`
+		Bytes   []byte  `strum:"43,46"`
+		IntPtr  *int    `strum:"46,48"`
+		StrPtr  *string `strum:"48,51"`
+		Array   [2]byte `strum:"51,53"`
+	}
+
+	expected := record{
+		Bool:    true,
+		Int:     -123,
+		Int8:    12,
+		Int64:   1234567,
+		Uint:    7,
+		Uint16:  65,
+		Float32: 1.5,
+		Float64: -2.25,
+		String:  "xyz",
+		Bytes:   []byte("def"),
+		IntPtr:  &one,
+		StrPtr:  &text,
+		Array:   [2]byte{'g', 'h'},
+	}
+
+	line, err := strum.Marshal(expected)
+	require.NoError(t, err)
+
+	var actual record
+
+	err = strum.Unmarshal(line, &actual)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestMarshal_nilPointers(t *testing.T) {
+	type record struct {
+		Int    *int              `strum:"0,3"`
+		Float  *float64          `strum:"3,7"`
+		Bool   *bool             `strum:"7,12"`
+		Cents  *strum.MinorUnits `strum:"12,17,dec=2"`
+		String *string           `strum:"17,20,omitempty"`
+	}
+
+	line, err := strum.Marshal(record{})
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat(" ", 20), line)
+
+	actual := record{Int: new(int), String: new(string)}
+
+	err = strum.Unmarshal(line, &actual)
+	require.NoError(t, err)
+	require.Equal(t, record{}, actual)
+}
+
+func TestMarshal_fillers(t *testing.T) {
+	type record struct {
+		_ struct{} `strum:"reclen=6"`
+		A string   `strum:"w=2"`
+		_ struct{} `strum:"skip=4"`
+	}
+
+	t.Run("writes trailing fillers and pads to the record length", func(t *testing.T) {
+		line, err := strum.Marshal(record{A: "ab"})
+		require.NoError(t, err)
+		require.Equal(t, "ab    ", line)
+
+		require.NoError(t, strum.Unmarshal(line, &record{}, strum.WithStrictRecordLength()))
+
+		line, err = strum.Marshal(&struct {
+			A string   `strum:"0,2"`
+			_ struct{} `strum:"2,5"`
+		}{A: "ab"})
+		require.NoError(t, err)
+		require.Equal(t, "ab   ", line)
+	})
+
+	t.Run("record length option", func(t *testing.T) {
+		line, err := strum.Marshal(record{A: "ab"}, strum.WithRecordLength(8))
+		require.NoError(t, err)
+		require.Equal(t, "ab      ", line)
+	})
+
+	t.Run("encoder output round trips", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		enc := strum.NewEncoder(&buf)
+		require.NoError(t, enc.Encode(record{A: "ab"}))
+		require.NoError(t, enc.Encode(record{A: "cd"}))
+		require.NoError(t, enc.Flush())
+
+		dec := strum.NewDecoder(&buf, strum.WithStrictRecordLength())
+
+		var test record
+
+		require.NoError(t, dec.Decode(&test))
+		require.Equal(t, "ab", test.A)
+		require.NoError(t, dec.Decode(&test))
+		require.Equal(t, "cd", test.A)
+	})
+
+	t.Run("nested fillers", func(t *testing.T) {
+		line, err := strum.Marshal(&struct {
+			Nested struct {
+				A string   `strum:"w=1"`
+				_ struct{} `strum:"skip=2"`
+			} `strum:"0,3"`
+			B string `strum:"3,4"`
+		}{B: "b"})
+		require.NoError(t, err)
+		require.Equal(t, "   b", line)
+	})
+}
+
+func TestMarshal_nested(t *testing.T) {
+	type address struct {
+		Number int    `strum:"0,3"`
+		Street string `strum:"3,8"`
+	}
+
+	t.Run("encodes nested structs into their slot", func(t *testing.T) {
+		test := struct {
+			Name string   `strum:"0,3"`
+			Home address  `strum:"3,11"`
+			Work *address `strum:"11,19"`
+		}{
+			Name: "Bob",
+			Home: address{123, "Main"},
+			Work: &address{456, "Queen"},
+		}
+
+		line, err := strum.Marshal(test)
+		require.NoError(t, err)
+		require.Equal(t, "Bob123Main 456Queen", line)
+	})
+
+	t.Run("writes nil pointers to structs as empty values", func(t *testing.T) {
+		test := struct {
+			Home *address `strum:"0,8"`
+		}{}
+
+		line, err := strum.Marshal(test)
+		require.NoError(t, err)
+		require.Equal(t, "        ", line)
+	})
+
+	t.Run("round trips nil pointers to structs", func(t *testing.T) {
+		type record struct {
+			Name string   `strum:"0,3"`
+			Home *address `strum:"3,11"`
+		}
+
+		line, err := strum.Marshal(record{Name: "Bob"})
+		require.NoError(t, err)
+		require.Equal(t, "Bob        ", line)
+
+		actual := record{Home: &address{}}
+
+		err = strum.Unmarshal(line, &actual)
+		require.NoError(t, err)
+		require.Equal(t, record{Name: "Bob"}, actual)
+	})
+
+	t.Run("error when nested values do not fit", func(t *testing.T) {
+		test := struct {
+			Home address `strum:"0,8"`
+		}{
+			Home: address{123, "Main Street"},
+		}
+
+		_, err := strum.Marshal(test)
+		require.ErrorContains(t, err, `field "Home"`)
+		require.ErrorContains(t, err, "does not fit")
+	})
+}
@@ -0,0 +1,221 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// decodeOccurs decodes each occurrence of the repeated group f found in rawVal into the
+// corresponding field of struct value.
+func (f *fieldPlan) decodeOccurs(rawVal string, value reflect.Value, o *options) error {
+	count, err := f.decodeCount(rawVal, value, o)
+	if err != nil {
+		return err
+	}
+
+	fv := value.Field(f.index)
+
+	err = f.reset(fv, count)
+	if err != nil {
+		return err
+	}
+
+	var errs FieldErrors
+
+	for i := 0; i < count; i++ {
+		elemVal, err := f.decodeOccurrence(rawVal, fv, i, o)
+		if err == nil {
+			continue
+		}
+
+		elem := *f.elem
+		elem.startIdx, elem.endIdx = i*f.elemWidth, (i+1)*f.elemWidth
+		errs = append(errs, elem.fieldErrors(err, elemVal, fmt.Sprintf("[%d]", i))...)
+
+		if !o.allErrors {
+			return errs[0]
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// reset prepares fv, the slice or array of the repeated group f, to receive count occurrences.
+func (f *fieldPlan) reset(fv reflect.Value, count int) error {
+	if fv.Kind() == reflect.Slice {
+		fv.Set(reflect.MakeSlice(fv.Type(), count, count))
+
+		return nil
+	}
+
+	if count > fv.Len() {
+		return classify(ErrOutOfBounds, fmt.Errorf(
+			"field %q cannot hold %d occurrences, only %d", f.name, count, fv.Len(),
+		))
+	}
+
+	fv.SetZero()
+
+	return nil
+}
+
+// decodeOccurrence decodes the i-th occurrence of the repeated group f found in rawVal into fv.
+// It returns the occurrence's substring.
+func (f *fieldPlan) decodeOccurrence(
+	rawVal string, fv reflect.Value, i int, o *options,
+) (string, error) {
+	elemVal, err := o.indexUnit().slice(rawVal, i*f.elemWidth, (i+1)*f.elemWidth)
+	if err != nil {
+		return "", fmt.Errorf(
+			"invalid indexes on field %q: occurrence %d out of bounds: %w", f.name, i, err,
+		)
+	}
+
+	err = f.elem.decode(elemVal, fv.Index(i), o)
+	if err != nil {
+		return elemVal, wrapError(err, "occurrence %d of field %q", i, f.name)
+	}
+
+	return elemVal, nil
+}
+
+// encodeOccurs encodes every occurrence of the repeated group f, a field of struct value.
+func (f *fieldPlan) encodeOccurs(value reflect.Value, o *options) (string, error) {
+	fv := value.Field(f.index)
+
+	n, slots, err := f.occurrences(value, fv)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < slots; i++ {
+		var strVal string
+
+		if i < n {
+			strVal, err = f.elem.encodeValue(fv.Index(i), f.elemWidth, o)
+			if err != nil {
+				return "", fmt.Errorf("occurrence %d of field %q: %w", i, f.name, err)
+			}
+		}
+
+		if o.indexUnit().measure(strVal) > f.elemWidth {
+			return "", fmt.Errorf(
+				"value %q does not fit occurrence %d of field %q of width %d",
+				strVal, i, f.name, f.elemWidth,
+			)
+		}
+
+		b.WriteString(pad(strVal, f.elemWidth, f.elem.numeric, o))
+	}
+
+	return b.String(), nil
+}
+
+// occurrences returns the number of occurrences of fv to encode and the number of slots to write.
+func (f *fieldPlan) occurrences(value, fv reflect.Value) (int, int, error) {
+	if f.occursIdx != -1 {
+		count, err := f.countedOccurrences(value, fv)
+
+		return count, count, err
+	}
+
+	if fv.Kind() == reflect.Array {
+		return f.occurs, f.occurs, nil
+	}
+
+	if n := fv.Len(); n > f.occurs {
+		return 0, 0, fmt.Errorf("field %q has %d occurrences, more than %d", f.name, n, f.occurs)
+	}
+
+	return fv.Len(), f.occurs, nil
+}
+
+// countedOccurrences returns the number of occurrences of fv to encode, which must agree with the
+// field holding the count.
+func (f *fieldPlan) countedOccurrences(value, fv reflect.Value) (int, error) {
+	count, err := f.count(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if n := fv.Len(); count > n || (fv.Kind() == reflect.Slice && count != n) {
+		return 0, fmt.Errorf(
+			"field %q has %d occurrences but %q is %d",
+			f.name, n, value.Type().Field(f.occursIdx).Name, count,
+		)
+	}
+
+	return count, nil
+}
+
+// decodeCount returns the number of occurrences of the repeated group f, a field of struct
+// value, to decode from rawVal. Counts that rawVal cannot hold are rejected before anything is
+// allocated for them.
+func (f *fieldPlan) decodeCount(rawVal string, value reflect.Value, o *options) (int, error) {
+	count, err := f.count(value)
+	if err != nil {
+		return 0, err
+	}
+
+	u := o.indexUnit()
+	n := u.measure(rawVal)
+
+	if f.elemWidth > 0 && count > n/f.elemWidth {
+		return 0, classify(ErrOutOfBounds, fmt.Errorf(
+			"invalid indexes on field %q: occurrence %d out of bounds: "+
+				"%d occurrences of width %d do not fit %d %s",
+			f.name, n/f.elemWidth, count, f.elemWidth, n, u,
+		))
+	}
+
+	return count, nil
+}
+
+// count returns the number of occurrences of the repeated group f, a field of struct value.
+func (f *fieldPlan) count(value reflect.Value) (int, error) {
+	if f.occursIdx == -1 {
+		return f.occurs, nil
+	}
+
+	cv := value.Field(f.occursIdx)
+
+	if cv.CanUint() {
+		if cv.Uint() > math.MaxInt32 {
+			return 0, classify(ErrOutOfBounds, fmt.Errorf(
+				"too many occurrences of field %q: %d", f.name, cv.Uint(),
+			))
+		}
+
+		return int(cv.Uint()), nil
+	}
+
+	if cv.Int() < 0 || cv.Int() > math.MaxInt32 {
+		return 0, classify(ErrOutOfBounds, fmt.Errorf(
+			"invalid number of occurrences of field %q: %d", f.name, cv.Int(),
+		))
+	}
+
+	return int(cv.Int()), nil
+}
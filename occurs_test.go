@@ -0,0 +1,272 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+type fee struct {
+	Code   string `strum:"0,2"`
+	Amount int    `strum:"2,5"`
+}
+
+func TestUnmarshal_occurs(t *testing.T) { //nolint:funlen,maintidx
+	t.Run("slice of builtin with fixed count", func(t *testing.T) {
+		test := &struct {
+			Val []int `strum:"0,occurs=3,elem=2"`
+		}{}
+
+		err := strum.Unmarshal("010203", test)
+		require.NoError(t, err)
+		require.Equal(t, []int{1, 2, 3}, test.Val)
+	})
+
+	t.Run("element width derived from the indexes", func(t *testing.T) {
+		test := &struct {
+			Val []string `strum:"1,7,occurs=3"`
+		}{}
+
+		err := strum.Unmarshal("_aabbcc_", test)
+		require.NoError(t, err)
+		require.Equal(t, []string{"aa", "bb", "cc"}, test.Val)
+	})
+
+	t.Run("array defaults to its length", func(t *testing.T) {
+		test := &struct {
+			Val [3]*int `strum:"0,6"`
+		}{}
+
+		err := strum.Unmarshal("010203", test)
+		require.NoError(t, err)
+		require.Equal(t, 3, *test.Val[2])
+	})
+
+	t.Run("slice of structs", func(t *testing.T) {
+		test := &struct {
+			Fees []fee `strum:"0,occurs=2,elem=5"`
+		}{}
+
+		err := strum.Unmarshal("AB001CD002", test)
+		require.NoError(t, err)
+		require.Equal(t, []fee{{"AB", 1}, {"CD", 2}}, test.Fees)
+	})
+
+	t.Run("count taken from another field", func(t *testing.T) {
+		test := &struct {
+			Count uint8 `strum:"0,1"`
+			Fees  []fee `strum:"1,occurs=Count,elem=5"`
+		}{}
+
+		err := strum.Unmarshal("2AB001CD002", test)
+		require.NoError(t, err)
+		require.Equal(t, []fee{{"AB", 1}, {"CD", 2}}, test.Fees)
+
+		err = strum.Unmarshal("0", test)
+		require.NoError(t, err)
+		require.Empty(t, test.Fees)
+	})
+
+	t.Run("array with count taken from another field", func(t *testing.T) {
+		test := &struct {
+			Count int    `strum:"0,1"`
+			Val   [3]int `strum:"1,occurs=Count,elem=1"`
+		}{}
+
+		err := strum.Unmarshal("212", test)
+		require.NoError(t, err)
+		require.Equal(t, [3]int{1, 2, 0}, test.Val)
+
+		err = strum.Unmarshal("41234", test)
+		require.ErrorContains(t, err, "cannot hold 4 occurrences")
+	})
+
+	t.Run("formats each occurrence", func(t *testing.T) {
+		test := &struct {
+			Val []string `strform:"upper" strum:"0,occurs=2,elem=2"`
+		}{}
+
+		err := strum.Unmarshal("abcd", test,
+			strum.WithFormatter("upper", func(s string) (string, error) {
+				return strings.ToUpper(s), nil
+			}),
+		)
+		require.NoError(t, err)
+		require.Equal(t, []string{"AB", "CD"}, test.Val)
+	})
+
+	t.Run("error when an occurrence is out of bounds", func(t *testing.T) {
+		test := &struct {
+			Val []int `strum:"0,occurs=3,elem=2"`
+		}{}
+
+		err := strum.Unmarshal("0102", test)
+		require.ErrorIs(t, err, strum.ErrOutOfBounds)
+		require.ErrorContains(t, err, "occurrence 2 out of bounds")
+	})
+
+	t.Run("error when the count does not fit the substring", func(t *testing.T) {
+		test := &struct {
+			N    int      `strum:"0,9"`
+			Fees []string `strum:"9,occurs=N,elem=4"`
+		}{}
+
+		err := strum.Unmarshal("9999999990001", test)
+		require.ErrorIs(t, err, strum.ErrOutOfBounds)
+		require.EqualError(t, err, `invalid indexes on field "Fees": occurrence 1 out of bounds: `+
+			`999999999 occurrences of width 4 do not fit 4 bytes`)
+		require.Nil(t, test.Fees)
+	})
+
+	t.Run("error when an occurrence cannot be decoded", func(t *testing.T) {
+		test := &struct {
+			Val []int `strum:"0,occurs=2,elem=2"`
+		}{}
+
+		err := strum.Unmarshal("01ab", test)
+		require.ErrorIs(t, err, strum.ErrParse)
+		require.EqualError(t, err, `occurrence 1 of field "Val": cannot assign value "ab": `+
+			`strconv.Atoi: parsing "ab": invalid syntax`)
+	})
+
+	t.Run("error when a struct occurrence cannot be decoded", func(t *testing.T) {
+		test := &struct {
+			Fees []fee `strum:"0,occurs=2,elem=5"`
+		}{}
+
+		err := strum.Unmarshal("AB001CDzzz", test)
+		require.EqualError(t, err,
+			`occurrence 1 of field "Fees": cannot assign value "zzz" to field "Amount": `+
+				`strconv.Atoi: parsing "zzz": invalid syntax`)
+
+		var fieldErr *strum.FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		require.Equal(t, "Fees[1].Amount", fieldErr.Path)
+	})
+
+	t.Run("error when the count is negative", func(t *testing.T) {
+		test := &struct {
+			Count int   `strum:"0,2"`
+			Val   []int `strum:"2,occurs=Count,elem=2"`
+		}{}
+
+		err := strum.Unmarshal("-1", test)
+		require.ErrorContains(t, err, "invalid number of occurrences")
+	})
+
+	t.Run("error when options are invalid", func(t *testing.T) {
+		tests := map[string]any{
+			"missing": &struct {
+				Val []int `strum:"0"`
+			}{},
+			"missing \"elem\"": &struct {
+				Val []int `strum:"0,occurs=2"`
+			}{},
+			"invalid \"elem\"": &struct {
+				Val []int `strum:"0,occurs=2,elem=x"`
+			}{},
+			"invalid \"occurs\"": &struct {
+				Val []int `strum:"0,occurs=Unknown,elem=2"`
+			}{},
+			"must be an integer or a preceding integer field": &struct {
+				Val   []int `strum:"0,occurs=Count,elem=2"`
+				Count int   `strum:"0,1"`
+			}{},
+			"invalid option": &struct {
+				Val []int `strum:"0,unknown=1"`
+			}{},
+			"duplicate option": &struct {
+				Val []int `strum:"0,occurs=1,occurs=1,elem=1"`
+			}{},
+			"not supported on this field": &struct {
+				Val int `strum:"0,occurs=1"`
+			}{},
+		}
+
+		for expected, test := range tests {
+			err := strum.Unmarshal("0102", test)
+			require.ErrorContains(t, err, expected)
+		}
+	})
+}
+
+func TestMarshal_occurs(t *testing.T) { //nolint:funlen
+	t.Run("pads missing occurrences", func(t *testing.T) {
+		test := struct {
+			Val []int `strum:"0,occurs=3,elem=2"`
+		}{Val: []int{1, 2}}
+
+		line, err := strum.Marshal(test)
+		require.NoError(t, err)
+		require.Equal(t, "010200", line)
+	})
+
+	t.Run("round trips structs with counts", func(t *testing.T) {
+		type record struct {
+			Count int    `strum:"0,1"`
+			Fees  []fee  `strum:"1,occurs=Count,elem=5"`
+			Codes [2]int `strum:"11,13"`
+		}
+
+		expected := record{
+			Count: 2,
+			Fees:  []fee{{"AB", 1}, {"CD", 2}},
+			Codes: [2]int{3, 4},
+		}
+
+		line, err := strum.Marshal(expected)
+		require.NoError(t, err)
+		require.Equal(t, "2AB001CD00234", line)
+
+		var actual record
+
+		err = strum.Unmarshal(line, &actual)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("error when there are too many occurrences", func(t *testing.T) {
+		test := struct {
+			Val []int `strum:"0,occurs=1,elem=2"`
+		}{Val: []int{1, 2}}
+
+		_, err := strum.Marshal(test)
+		require.ErrorContains(t, err, "more than 1")
+	})
+
+	t.Run("error when the count does not match", func(t *testing.T) {
+		test := struct {
+			Count int   `strum:"0,1"`
+			Val   []int `strum:"1,occurs=Count,elem=2"`
+		}{Count: 1, Val: []int{1, 2}}
+
+		_, err := strum.Marshal(test)
+		require.ErrorContains(t, err, `field "Val" has 2 occurrences but "Count" is 1`)
+	})
+
+	t.Run("error when an occurrence does not fit", func(t *testing.T) {
+		test := struct {
+			Val []int `strum:"0,occurs=1,elem=2"`
+		}{Val: []int{100}}
+
+		_, err := strum.Marshal(test)
+		require.ErrorContains(t, err, "does not fit occurrence 0")
+	})
+}
@@ -0,0 +1,174 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// packing is the representation of numeric fields in the input.
+type packing int
+
+const (
+	// noPacking is for numbers written as text.
+	noPacking packing = iota
+	// packedDecimal is for numbers written in packed decimal (COMP-3): two digits per byte and a
+	// sign in the last nibble.
+	packedDecimal
+	// zonedDecimal is for numbers written one digit per byte, with the sign overpunched on the
+	// last digit.
+	zonedDecimal
+)
+
+const (
+	positiveOverpunch = "{ABCDEFGHI"
+	negativeOverpunch = "}JKLMNOPQR"
+)
+
+var (
+	errInvalidPacked = errors.New("invalid packed decimal")
+	errInvalidZoned  = errors.New("invalid zoned decimal")
+)
+
+// unpack returns the textual representation of s, a number represented according to f.packing.
+func (f *fieldPlan) unpack(s string) (string, error) {
+	switch f.packing {
+	case packedDecimal:
+		return unpackPacked(s)
+	case zonedDecimal:
+		return unpackZoned(s)
+	default:
+		return s, nil
+	}
+}
+
+// pack represents s, the textual representation of a number, according to f.packing. The result
+// is width bytes long unless width is -1. Empty values, eg. nil pointers, are left as is so that
+// their field is left blank.
+func (f *fieldPlan) pack(s string, width int) (string, error) {
+	if f.packing == noPacking || s == "" {
+		return s, nil
+	}
+
+	sign, digits := s[:0], s
+
+	if s[0] == '-' || s[0] == '+' {
+		sign, digits = s[:1], s[1:]
+	}
+
+	if strings.Trim(digits, "0123456789") != "" {
+		return "", fmt.Errorf("cannot pack value %q", s)
+	}
+
+	if f.packing == packedDecimal {
+		return packPacked(sign == "-", digits, width)
+	}
+
+	return packZoned(sign == "-", digits), nil
+}
+
+func unpackPacked(s string) (string, error) {
+	if s == "" {
+		return "", errInvalidPacked
+	}
+
+	digits := make([]byte, 0, 2*len(s))
+
+	for i := 0; i < len(s); i++ {
+		digits = append(digits, '0'+s[i]>>4, '0'+s[i]&0x0f)
+	}
+
+	sign := digits[len(digits)-1] - '0'
+	digits = digits[:len(digits)-1]
+
+	for _, d := range digits {
+		if d > '9' {
+			return "", errInvalidPacked
+		}
+	}
+
+	switch sign {
+	case 0x0a, 0x0c, 0x0e, 0x0f:
+		return string(digits), nil
+	case 0x0b, 0x0d:
+		return "-" + string(digits), nil
+	default:
+		return "", errInvalidPacked
+	}
+}
+
+func packPacked(negative bool, digits string, width int) (string, error) {
+	if width == -1 {
+		width = len(digits)/2 + 1
+	}
+
+	if len(digits) > 2*width-1 {
+		return "", fmt.Errorf("value %q does not fit %d bytes of packed decimal", digits, width)
+	}
+
+	nibbles := strings.Repeat("0", 2*width-1-len(digits)) + digits
+	b := make([]byte, width)
+
+	for i := 0; i < width-1; i++ {
+		b[i] = (nibbles[2*i]-'0')<<4 | (nibbles[2*i+1] - '0')
+	}
+
+	sign := byte(0x0c)
+	if negative {
+		sign = 0x0d
+	}
+
+	b[width-1] = (nibbles[2*width-2]-'0')<<4 | sign
+
+	return string(b), nil
+}
+
+func unpackZoned(s string) (string, error) {
+	if s == "" {
+		return "", errInvalidZoned
+	}
+
+	digits, last := s[:len(s)-1], s[len(s)-1]
+
+	if strings.Trim(digits, "0123456789") != "" {
+		return "", errInvalidZoned
+	}
+
+	switch {
+	case last >= '0' && last <= '9':
+		return s, nil
+	case strings.IndexByte(positiveOverpunch, last) >= 0:
+		return digits + string(rune('0'+strings.IndexByte(positiveOverpunch, last))), nil
+	case strings.IndexByte(negativeOverpunch, last) >= 0:
+		return "-" + digits + string(rune('0'+strings.IndexByte(negativeOverpunch, last))), nil
+	default:
+		return "", errInvalidZoned
+	}
+}
+
+func packZoned(negative bool, digits string) string {
+	if digits == "" {
+		digits = "0"
+	}
+
+	overpunch := positiveOverpunch
+	if negative {
+		overpunch = negativeOverpunch
+	}
+
+	return digits[:len(digits)-1] + string(overpunch[digits[len(digits)-1]-'0'])
+}
@@ -0,0 +1,175 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+func TestUnmarshal_packed(t *testing.T) { //nolint:funlen
+	t.Run("packed decimal", func(t *testing.T) {
+		test := &struct {
+			Positive int64            `strum:"0,3,packed"`
+			Negative *int             `strum:"3,5,packed"`
+			Unsigned uint32           `strum:"5,6,packed"`
+			Amount   strum.MinorUnits `strum:"6,9,packed,dec=2"`
+			Rate     float64          `strum:"9,11,packed,dec=2"`
+		}{}
+
+		err := strum.Unmarshal("\x01\x23\x4c\x12\x3d\x7f\x00\x12\x3c\x15\x0c", test)
+		require.NoError(t, err)
+		require.Equal(t, int64(1234), test.Positive)
+		require.Equal(t, -123, *test.Negative)
+		require.Equal(t, uint32(7), test.Unsigned)
+		require.Equal(t, strum.MinorUnits(123), test.Amount)
+		require.InDelta(t, 1.5, test.Rate, 0)
+	})
+
+	t.Run("zoned decimal", func(t *testing.T) {
+		test := &struct {
+			PositiveZero int              `strum:"0,6,zoned"`
+			Negative     int              `strum:"6,14,zoned"`
+			Unsigned     uint             `strum:"14,17,zoned"`
+			Amount       strum.MinorUnits `strum:"17,22,zoned,dec=2"`
+		}{}
+
+		err := strum.Unmarshal("00012{0001234J1230012}", test)
+		require.NoError(t, err)
+		require.Equal(t, 120, test.PositiveZero)
+		require.Equal(t, -12341, test.Negative)
+		require.Equal(t, uint(123), test.Unsigned)
+		require.Equal(t, strum.MinorUnits(-120), test.Amount)
+	})
+
+	t.Run("applies to each occurrence", func(t *testing.T) {
+		test := &struct {
+			Val []int `strum:"0,occurs=2,elem=2,zoned"`
+		}{}
+
+		err := strum.Unmarshal("1A2J", test)
+		require.NoError(t, err)
+		require.Equal(t, []int{11, -21}, test.Val)
+	})
+
+	t.Run("error when the value is invalid", func(t *testing.T) {
+		tests := map[string]any{
+			"invalid packed decimal": &struct {
+				Val int `strum:"0,2,packed"`
+			}{},
+			"invalid zoned decimal": &struct {
+				Val int `strum:"0,2,zoned"`
+			}{},
+		}
+
+		for expected, test := range tests {
+			err := strum.Unmarshal("ab", test)
+			require.ErrorContains(t, err, expected)
+		}
+
+		err := strum.Unmarshal("\x1a\x0c", &struct {
+			Val int `strum:"0,2,packed"`
+		}{})
+		require.ErrorContains(t, err, "invalid packed decimal")
+
+		err = strum.Unmarshal("\x12\x34", &struct {
+			Val int `strum:"0,2,packed"`
+		}{})
+		require.ErrorContains(t, err, "invalid packed decimal")
+	})
+
+	t.Run("error when the options are invalid", func(t *testing.T) {
+		tests := map[string]any{
+			"mutually exclusive": &struct {
+				Val int `strum:"0,2,packed,zoned"`
+			}{},
+			"only supported on numeric fields": &struct {
+				Val string `strum:"0,2,packed"`
+			}{},
+			`invalid option "packed=1"`: &struct {
+				Val int `strum:"0,2,packed=1"`
+			}{},
+		}
+
+		for expected, test := range tests {
+			err := strum.Unmarshal("12", test)
+			require.ErrorContains(t, err, expected)
+		}
+	})
+}
+
+func TestMarshal_packed(t *testing.T) {
+	type record struct {
+		Packed    int              `strum:"0,3,packed"`
+		Negative  *int             `strum:"3,5,packed"`
+		Missing   *int             `strum:"5,7,packed"`
+		Zoned     strum.MinorUnits `strum:"7,12,zoned,dec=2"`
+		ZonedNeg  float64          `strum:"12,16,zoned,dec=1"`
+		Occurring []int            `strum:"16,occurs=2,elem=1,packed"`
+	}
+
+	negative := -123
+	expected := record{
+		Packed:    1234,
+		Negative:  &negative,
+		Zoned:     120,
+		ZonedNeg:  -1.5,
+		Occurring: []int{1, 2},
+	}
+
+	line, err := strum.Marshal(expected)
+	require.NoError(t, err)
+	require.Equal(t, "\x01\x23\x4c\x12\x3d  0012{001N\x1c\x2c", line)
+
+	// nil pointers are left blank and decoded back as nil
+	actual := record{Missing: new(int)}
+
+	err = strum.Unmarshal(line, &actual)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+
+	t.Run("round trips nil pointers in code pages", func(t *testing.T) {
+		test := struct {
+			Val *int `strum:"0,2,packed"`
+		}{}
+
+		line, err := strum.Marshal(test, strum.WithCodePage("IBM037"))
+		require.NoError(t, err)
+		require.Equal(t, "\x40\x40", line)
+
+		test.Val = new(int)
+
+		err = strum.Unmarshal(line, &test, strum.WithCodePage("IBM037"))
+		require.NoError(t, err)
+		require.Nil(t, test.Val)
+	})
+
+	t.Run("error when the value does not fit", func(t *testing.T) {
+		_, err := strum.Marshal(struct {
+			Val int `strum:"0,1,packed"`
+		}{Val: 10})
+		require.ErrorContains(t, err, "does not fit 1 bytes of packed decimal")
+	})
+
+	t.Run("error when the value cannot be packed", func(t *testing.T) {
+		_, err := strum.Marshal(struct {
+			Val float64 `strum:"0,2,zoned"`
+		}{Val: 1.5})
+		require.ErrorContains(t, err, `cannot pack value "1.5"`)
+	})
+}
@@ -0,0 +1,230 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"runtime"
+	"sync"
+)
+
+// batchSize is the number of lines decoded at once by the workers of ParallelRecords.
+const batchSize = 256
+
+// WithWorkers sets the number of goroutines that decode lines in ParallelRecords. The default is
+// runtime.GOMAXPROCS(0).
+func WithWorkers(n int) Option {
+	return func(o *options) {
+		o.workers = n
+	}
+}
+
+// batch is a sequence of consecutive lines decoded by the same worker.
+type batch[T any] struct {
+	lines   []string
+	records []Record[T]
+	errs    []error
+	// err is the error that ended the input after the lines, if any.
+	err error
+	// done is closed once the lines are decoded.
+	done chan struct{}
+}
+
+// ParallelRecords is like NumberedRecords but decodes lines concurrently (see WithWorkers). Values
+// are still yielded in the order of the lines.
+//
+// Lines are read ahead of the iteration and decoded in batches. The number of batches in flight
+// is bounded, so that memory usage does not depend on the size of the input. Reading stops when
+// ctx is done, which yields ctx's error and ends the iteration, or when breaking out of the loop.
+// In both cases the iteration returns once the goroutines have stopped, ie. once the pending read
+// on r, if any, has returned.
+func ParallelRecords[T any](
+	ctx context.Context, r io.Reader, opts ...Option,
+) iter.Seq2[Record[T], error] {
+	return func(yield func(Record[T], error) bool) {
+		p, err := Compile[T](opts...)
+		if err != nil {
+			yield(Record[T]{}, err)
+
+			return
+		}
+
+		workers := p.options.workers
+		if workers <= 0 {
+			workers = runtime.GOMAXPROCS(0)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+
+		var wg sync.WaitGroup
+
+		defer func() {
+			cancel()
+			wg.Wait()
+		}()
+
+		jobs := make(chan *batch[T])
+		ordered := make(chan *batch[T], 2*workers)
+
+		wg.Add(1 + workers)
+
+		go func() {
+			defer wg.Done()
+			readBatches(ctx, NewDecoder(r), jobs, ordered)
+		}()
+
+		for range workers {
+			go func() {
+				defer wg.Done()
+
+				for b := range jobs {
+					b.decode(p)
+				}
+			}()
+		}
+
+		yieldBatches(ctx, ordered, yield)
+	}
+}
+
+// readBatches reads batches of lines from d and sends them both to the workers, through jobs,
+// and to the consumer, through ordered. It closes both channels when done.
+func readBatches[T any](ctx context.Context, d *Decoder, jobs, ordered chan<- *batch[T]) {
+	defer close(ordered)
+	defer close(jobs)
+
+	for {
+		b := readBatch[T](d)
+		if b == nil {
+			return
+		}
+
+		// reserving a place in ordered first bounds the number of batches in flight
+		if !send(ctx, ordered, b) || !send(ctx, jobs, b) || b.err != nil {
+			return
+		}
+	}
+}
+
+// send sends b to ch. It returns false if ctx is done first.
+func send[T any](ctx context.Context, ch chan<- *batch[T], b *batch[T]) bool {
+	select {
+	case ch <- b:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// readBatch reads the next batch of lines from d. It returns nil at the end of the input.
+func readBatch[T any](d *Decoder) *batch[T] {
+	b := &batch[T]{done: make(chan struct{})}
+
+	for len(b.lines) < batchSize {
+		line, text, err := d.read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			b.err = err
+
+			break
+		}
+
+		b.lines = append(b.lines, text)
+		b.records = append(b.records, Record[T]{Line: line})
+	}
+
+	if len(b.lines) == 0 && b.err == nil {
+		return nil
+	}
+
+	return b
+}
+
+// decode decodes the lines of the batch with p.
+func (b *batch[T]) decode(p *Parser[T]) {
+	defer close(b.done)
+
+	b.errs = make([]error, len(b.lines))
+
+	for i := range b.lines {
+		err := p.Unmarshal(b.lines[i], &b.records[i].Value)
+		if err != nil {
+			b.errs[i] = fmt.Errorf("line %d: %w", b.records[i].Line, err)
+		}
+	}
+}
+
+// yieldBatches yields the records of the batches received from ordered as they are decoded.
+func yieldBatches[T any](
+	ctx context.Context, ordered <-chan *batch[T], yield func(Record[T], error) bool,
+) {
+	for {
+		// select picks randomly among ready cases, so check for cancellation first
+		if err := ctx.Err(); err != nil {
+			yield(Record[T]{}, err)
+
+			return
+		}
+
+		select {
+		case b, ok := <-ordered:
+			if !ok || !b.yield(ctx, yield) {
+				return
+			}
+		case <-ctx.Done():
+			yield(Record[T]{}, ctx.Err())
+
+			return
+		}
+	}
+}
+
+// yield yields the records of the batch once decoded. It returns false if the iteration ends.
+func (b *batch[T]) yield(ctx context.Context, yield func(Record[T], error) bool) bool {
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		yield(Record[T]{}, ctx.Err())
+
+		return false
+	}
+
+	for i := range b.records {
+		if err := ctx.Err(); err != nil {
+			yield(Record[T]{}, err)
+
+			return false
+		}
+
+		if !yield(b.records[i], b.errs[i]) {
+			return false
+		}
+	}
+
+	if b.err != nil {
+		yield(Record[T]{}, b.err)
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,149 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+func TestParallelRecords(t *testing.T) { //nolint:funlen,gocyclo
+	t.Run("yields every line in order", func(t *testing.T) {
+		var input strings.Builder
+
+		const n = 2000
+
+		for i := range n {
+			if i%7 == 3 {
+				input.WriteString("abcxyz\n")
+			} else {
+				fmt.Fprintf(&input, "abc%03d\n", i%1000)
+			}
+		}
+
+		line := 0
+
+		records := strum.ParallelRecords[decoderRecord](
+			context.Background(), strings.NewReader(input.String()), strum.WithWorkers(4),
+		)
+
+		for rec, err := range records {
+			line++
+			require.Equal(t, line, rec.Line)
+
+			if (line-1)%7 == 3 {
+				require.ErrorIs(t, err, strum.ErrParse)
+				require.ErrorContains(t, err, fmt.Sprintf("line %d: ", line))
+
+				continue
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, decoderRecord{"abc", (line - 1) % 1000}, rec.Value)
+		}
+
+		require.Equal(t, n, line)
+	})
+
+	t.Run("break stops reading", func(t *testing.T) {
+		// reading the whole input takes over 250 reads
+		r := &countingReader{r: strings.NewReader(strings.Repeat("abc001\n", 150000))}
+
+		records := strum.ParallelRecords[decoderRecord](context.Background(), r, strum.WithWorkers(1))
+
+		for rec, err := range records {
+			require.NoError(t, err)
+			require.Equal(t, strum.Record[decoderRecord]{Line: 1, Value: decoderRecord{"abc", 1}}, rec)
+
+			break
+		}
+
+		require.Less(t, r.reads.Load(), int64(32))
+	})
+
+	t.Run("read errors end the iteration", func(t *testing.T) {
+		expected := errors.New("test")
+		r := io.MultiReader(strings.NewReader("abc001\n"), &errReader{err: expected})
+
+		var errs []error
+
+		for _, err := range strum.ParallelRecords[decoderRecord](context.Background(), r) {
+			errs = append(errs, err)
+		}
+
+		require.Len(t, errs, 2)
+		require.NoError(t, errs[0])
+		require.ErrorIs(t, errs[1], expected)
+	})
+
+	t.Run("cancellation ends the iteration", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		input := strings.NewReader(strings.Repeat("abc001\n", 10000))
+
+		var (
+			count int
+			last  error
+		)
+
+		for _, err := range strum.ParallelRecords[decoderRecord](ctx, input) {
+			if err != nil {
+				last = err
+
+				continue
+			}
+
+			count++
+			if count == 10 {
+				cancel()
+			}
+		}
+
+		require.ErrorIs(t, last, context.Canceled)
+		require.Equal(t, 10, count)
+	})
+
+	t.Run("compile errors end the iteration", func(t *testing.T) {
+		var errs []error
+
+		for _, err := range strum.ParallelRecords[int](context.Background(), strings.NewReader("1\n")) {
+			errs = append(errs, err)
+		}
+
+		require.Len(t, errs, 1)
+		require.ErrorIs(t, errs[0], strum.ErrNotStruct)
+	})
+}
+
+type countingReader struct {
+	r     io.Reader
+	reads atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	r.reads.Add(1)
+
+	return r.r.Read(p)
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Parser decodes lines into values of the struct type T. Its layout is compiled once by Compile
+// and its options are applied once, so that only the contents of lines can make it fail.
+//
+// A Parser is safe for concurrent use.
+type Parser[T any] struct {
+	plan    *plan
+	options options
+}
+
+// Compile compiles the layout of the struct type T with the given options (see Unmarshal). It
+// returns an error if T is not a struct or if its tags are invalid.
+func Compile[T any](opts ...Option) (*Parser[T], error) {
+	options := *defaultOptions
+
+	for i := range opts {
+		opts[i](&options)
+	}
+
+	err := validateCodePage(&options)
+	if err != nil {
+		return nil, err
+	}
+
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s", ErrNotStruct, t.Kind())
+	}
+
+	p, err := compile(t, &options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Parser[T]{plan: p, options: options}, nil
+}
+
+// MustCompile is like Compile but panics on error. It simplifies the initialization of global
+// variables, so that invalid layouts are caught at startup:
+//
+//	var txnParser = strum.MustCompile[Txn]()
+func MustCompile[T any](opts ...Option) *Parser[T] {
+	p, err := Compile[T](opts...)
+	if err != nil {
+		panic(fmt.Sprintf("strum: Compile[%s]: %v", reflect.TypeFor[T](), err))
+	}
+
+	return p
+}
+
+// Parse decodes line into a new value of type T (see Unmarshal).
+func (p *Parser[T]) Parse(line string) (T, error) {
+	var v T
+
+	err := p.Unmarshal(line, &v)
+
+	return v, err
+}
+
+// Unmarshal decodes line into the value pointed to by v (see Unmarshal).
+func (p *Parser[T]) Unmarshal(line string, v *T) error {
+	if v == nil {
+		return ErrNilPointer
+	}
+
+	return p.plan.unmarshal(line, reflect.ValueOf(v).Elem(), &p.options)
+}
+
+// Parse decodes line into a new value of the struct type T (see Unmarshal).
+func Parse[T any](line string, opts ...Option) (T, error) {
+	var v T
+
+	err := Unmarshal(line, &v, opts...)
+
+	return v, err
+}
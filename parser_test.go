@@ -0,0 +1,135 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("decodes into a new value", func(t *testing.T) {
+		r, err := strum.Parse[decoderRecord]("abc001")
+		require.NoError(t, err)
+		require.Equal(t, decoderRecord{"abc", 1}, r)
+	})
+
+	t.Run("applies options", func(t *testing.T) {
+		_, err := strum.Parse[decoderRecord](
+			"abc0010", strum.WithRecordLength(6), strum.WithStrictRecordLength(),
+		)
+		require.ErrorIs(t, err, strum.ErrRecordLength)
+	})
+
+	t.Run("error when T is not a struct", func(t *testing.T) {
+		_, err := strum.Parse[int]("1")
+		require.ErrorIs(t, err, strum.ErrNotStruct)
+	})
+}
+
+func TestCompile(t *testing.T) { //nolint:funlen
+	t.Run("parses lines", func(t *testing.T) {
+		p, err := strum.Compile[decoderRecord]()
+		require.NoError(t, err)
+
+		r, err := p.Parse("abc001")
+		require.NoError(t, err)
+		require.Equal(t, decoderRecord{"abc", 1}, r)
+
+		_, err = p.Parse("abcxyz")
+		require.ErrorIs(t, err, strum.ErrParse)
+
+		require.NoError(t, p.Unmarshal("def002", &r))
+		require.Equal(t, decoderRecord{"def", 2}, r)
+
+		require.ErrorIs(t, p.Unmarshal("def002", nil), strum.ErrNilPointer)
+	})
+
+	t.Run("applies options once", func(t *testing.T) {
+		p, err := strum.Compile[validRecord](strum.WithStrictRecordLength())
+		require.NoError(t, err)
+
+		_, err = p.Parse("AB 0012xy ok")
+		require.NoError(t, err)
+
+		_, err = p.Parse("AB 0012xy")
+		require.ErrorIs(t, err, strum.ErrRecordLength)
+	})
+
+	t.Run("is safe for concurrent use", func(t *testing.T) {
+		p := strum.MustCompile[decoderRecord]()
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				r, err := p.Parse("abc001")
+				require.NoError(t, err)
+				require.Equal(t, decoderRecord{"abc", 1}, r)
+			}()
+		}
+
+		wg.Wait()
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := strum.Compile[*decoderRecord]()
+		require.ErrorIs(t, err, strum.ErrNotStruct)
+
+		_, err = strum.Compile[struct {
+			Val int `strum:"x"`
+		}]()
+		require.ErrorIs(t, err, strum.ErrInvalidTag)
+
+		_, err = strum.Compile[struct {
+			Val int `strum:"10,9"`
+		}]()
+		require.ErrorIs(t, err, strum.ErrInvalidTag)
+		require.ErrorContains(t, err, "end index must be greater or equal to start index: 9 < 10")
+
+		_, err = strum.Compile[struct {
+			Val int `strum:"-2,3"`
+		}]()
+		require.ErrorIs(t, err, strum.ErrInvalidTag)
+		require.ErrorContains(t, err, "start index -2 must not be negative")
+
+		_, err = strum.Compile[struct {
+			Val int `strum:"0,-2"`
+		}]()
+		require.ErrorIs(t, err, strum.ErrInvalidTag)
+
+		_, err = strum.Compile[struct {
+			Val int `strum:"15,13"`
+		}](strum.WithOneBasedIndexes())
+		require.ErrorIs(t, err, strum.ErrInvalidTag)
+
+		_, err = strum.Compile[decoderRecord](strum.WithCodePage("unknown"))
+		require.ErrorContains(t, err, `unknown code page "unknown"`)
+	})
+
+	t.Run("MustCompile panics on error", func(t *testing.T) {
+		require.PanicsWithValue(t, "strum: Compile[int]: not a struct: int", func() {
+			strum.MustCompile[int]()
+		})
+	})
+}
@@ -0,0 +1,589 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// plan is the compiled layout of a struct type. Plans are immutable once compiled and are
+// shared by all goroutines.
+type plan struct {
+	fields []fieldPlan
+	// width is the largest end index of the fields and fillers.
+	width int
+	// recordLength is the length of records declared with the "reclen" option, or 0.
+	recordLength int
+	// fillers are the ranges reserved by fillers and blank fields.
+	fillers []span
+}
+
+// span is a range of indexes. endIdx is -1 if the range extends to the end of the line.
+type span struct {
+	startIdx int
+	endIdx   int
+}
+
+// fieldPlan is the compiled layout of a single struct field.
+type fieldPlan struct {
+	field    Field
+	name     string
+	index    int
+	startIdx int
+	// endIdx is -1 if the field extends to the end of the line.
+	endIdx       int
+	formatter    string
+	hasFormatter bool
+	valuer       primitiveValuer
+	encoder      primitiveEncoder
+	numeric      bool
+	// nested is the plan of struct fields. Their indexes are relative to the field's substring.
+	nested *plan
+	// ptr is true if the field is a pointer to the type described by nested.
+	ptr bool
+	// elem is the layout of each occurrence of repeated groups (slices and arrays).
+	elem *fieldPlan
+	// element is true for the layout of the occurrences of a repeated group.
+	element bool
+	// occurs is the fixed number of occurrences of repeated groups, or -1.
+	occurs int
+	// occursIdx is the index of the field holding the number of occurrences, or -1.
+	occursIdx int
+	// elemWidth is the width of each occurrence of repeated groups.
+	elemWidth int
+	// unmarshaler is true if the field implements FieldUnmarshaler or encoding.TextUnmarshaler.
+	unmarshaler bool
+	// marshaler is true if the field implements FieldMarshaler or encoding.TextMarshaler.
+	marshaler bool
+	// timeLayout is the layout of time.Time fields.
+	timeLayout string
+	// packing is the representation of numeric fields.
+	packing packing
+	// retains is true if decoded values may reference the field's substring: strings, []byte
+	// and fields that implement FieldUnmarshaler or encoding.TextUnmarshaler.
+	retains bool
+	// blanks are the substrings decoded as the zero value or a default, if any.
+	blanks *blankValues
+	// nilIfBlank is true for pointer fields whose blank substrings, as written by Marshal for nil
+	// pointers, are decoded as nil.
+	nilIfBlank bool
+}
+
+// planKey identifies a plan. It includes every option that affects the layout.
+type planKey struct {
+	t         reflect.Type
+	delimiter string
+	oneBased  bool
+}
+
+var plans sync.Map // map[planKey]*plan
+
+// compile returns the plan of struct type t, compiling and caching it if necessary.
+func compile(t reflect.Type, o *options) (*plan, error) {
+	return compileType(t, o, nil)
+}
+
+// compileType compiles struct type t, which is nested inside the given parent types.
+func compileType(t reflect.Type, o *options, parents []reflect.Type) (*plan, error) {
+	key := planKey{t: t, delimiter: o.delimiter, oneBased: o.oneBased}
+
+	if p, ok := plans.Load(key); ok {
+		return p.(*plan), nil //nolint:forcetypeassert
+	}
+
+	for i := range parents {
+		if parents[i] == t {
+			return nil, fmt.Errorf("recursive layout of type %s", t)
+		}
+	}
+
+	p, err := newPlan(t, o, append(parents, t))
+	if err != nil {
+		return nil, err
+	}
+
+	actual, _ := plans.LoadOrStore(key, p)
+
+	return actual.(*plan), nil //nolint:forcetypeassert
+}
+
+func newPlan(t reflect.Type, o *options, parents []reflect.Type) (*plan, error) {
+	p := &plan{}
+	// cursor is the end index of the preceding field, or -1 if it extends to the end of the line
+	cursor := 0
+
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok, err := parseField(t.Field(i), o, cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		cursor, err = p.add(t, i, &tag, cursor, o, parents)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// parseField parses the tag of the struct field sf, placed after cursor in sequential layouts.
+// It returns false if the field is not part of the layout.
+func parseField(sf reflect.StructField, o *options, cursor int) (fieldTag, bool, error) {
+	tagValue, ok := sf.Tag.Lookup(TagName)
+	if !ok || tagValue == ignoreTag {
+		return fieldTag{}, false, nil
+	}
+
+	if !sf.IsExported() && sf.Name != "_" {
+		return fieldTag{}, false, classify(ErrInvalidLayout, fmt.Errorf(
+			"unexported field %q cannot be tagged %s: export it or tag it %s",
+			sf.Name, tagString(tagValue), tagString(ignoreTag),
+		))
+	}
+
+	tag, err := parseTag(tagValue, o.delimiter, o.oneBased, cursor)
+	if err != nil {
+		return fieldTag{}, false, fmt.Errorf(
+			"format error on field %q tagged %s: %w",
+			sf.Name, tagString(tagValue), classify(ErrInvalidTag, err),
+		)
+	}
+
+	return tag, true, nil
+}
+
+// add adds the i-th field of struct type t, whose parsed tag is given, to the plan. It returns the
+// cursor of the next field.
+func (p *plan) add(
+	t reflect.Type, i int, tag *fieldTag, cursor int, o *options, parents []reflect.Type,
+) (int, error) {
+	sf := t.Field(i)
+
+	if tag.skip > 0 {
+		p.addFiller(span{tag.startIdx - tag.skip, tag.startIdx})
+	}
+
+	switch {
+	case tag.recordLength != 0 && sf.Name != "_":
+		return 0, fmt.Errorf(
+			"format error on field %q tagged %s: %w", sf.Name, tagString(sf.Tag.Get(TagName)),
+			classify(ErrInvalidTag, fmt.Errorf(
+				"option %q is only supported on blank fields", recordLengthOption,
+			)),
+		)
+	case tag.recordLength != 0:
+		p.recordLength = tag.recordLength
+
+		return cursor, nil
+	case tag.filler:
+		return tag.endIdx, nil
+	case sf.Name == "_":
+		// blank fields only take up space
+		p.addFiller(span{tag.startIdx, tag.endIdx})
+
+		return tag.endIdx, nil
+	default:
+		return p.addField(t, i, tag, o, parents)
+	}
+}
+
+// addField compiles the i-th field of struct type t, whose parsed tag is given, and adds it to
+// the plan unless its type is not supported. It returns the cursor of the next field.
+func (p *plan) addField(
+	t reflect.Type, i int, tag *fieldTag, o *options, parents []reflect.Type,
+) (int, error) {
+	f, ok, err := newFieldPlan(t, i, tag, o, parents)
+	if err != nil {
+		return 0, err
+	}
+
+	if ok {
+		p.fields = append(p.fields, *f)
+		p.width = max(p.width, f.endIdx)
+	}
+
+	return tag.endIdx, nil
+}
+
+// addFiller adds the range s, reserved by a filler or a blank field, to the plan.
+func (p *plan) addFiller(s span) {
+	p.fillers = append(p.fillers, s)
+	p.width = max(p.width, s.endIdx)
+}
+
+// newFieldPlan compiles the i-th field of struct type t, whose parsed tag is given. It returns
+// false if the field's type is not supported.
+func newFieldPlan(
+	t reflect.Type, i int, tag *fieldTag, o *options, parents []reflect.Type,
+) (*fieldPlan, bool, error) {
+	sf := t.Field(i)
+	f := &fieldPlan{
+		name:      sf.Name,
+		index:     i,
+		startIdx:  tag.startIdx,
+		endIdx:    tag.endIdx,
+		occurs:    -1,
+		occursIdx: -1,
+		field: Field{
+			Name:     sf.Name,
+			StartIdx: tag.startIdx,
+			EndIdx:   tag.endIdx,
+			Tag:      sf.Tag,
+		},
+	}
+
+	f.formatter, f.hasFormatter = sf.Tag.Lookup(FormatterTagName)
+	f.timeLayout = sf.Tag.Get(TimeLayoutTagName)
+
+	ok, err := f.resolve(sf.Type, o, parents)
+	if err != nil {
+		return nil, false, fmt.Errorf("field %q: %w", f.name, err)
+	}
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	err = f.applyOptions(t, sf.Type, tag)
+	if err != nil {
+		return nil, false, fmt.Errorf(
+			"format error on field %q tagged %s: %w",
+			f.name, tagString(sf.Tag.Get(TagName)), classify(ErrInvalidTag, err),
+		)
+	}
+
+	return f, true, nil
+}
+
+// applyOptions configures f, a field of type ft in struct type t, according to the options of
+// its tag. Options that apply to values are applied to each occurrence of repeated groups.
+func (f *fieldPlan) applyOptions(t, ft reflect.Type, tag *fieldTag) error {
+	target := f
+
+	if f.elem != nil {
+		err := f.resolveOccurs(t, ft, tag)
+		if err != nil {
+			return err
+		}
+
+		f.elem.field = f.field
+		target, ft = f.elem, ft.Elem()
+	}
+
+	err := target.applyDecimals(ft, tag)
+	if err != nil {
+		return err
+	}
+
+	err = target.applyPacking(tag)
+	if err != nil {
+		return err
+	}
+
+	err = target.applyBlanks(tag)
+	if err != nil {
+		return err
+	}
+
+	if len(tag.options) > 0 {
+		unsupported := slices.Sorted(maps.Keys(tag.options))
+
+		return fmt.Errorf("option %q is not supported on this field", unsupported[0])
+	}
+
+	return nil
+}
+
+// applyDecimals configures the number of implied decimal places of numeric fields of type t.
+func (f *fieldPlan) applyDecimals(t reflect.Type, tag *fieldTag) error {
+	value, ok := tag.take(decOption)
+	if !ok {
+		return nil
+	}
+
+	dec, err := strconv.Atoi(value)
+	if err != nil || dec < 0 {
+		return fmt.Errorf("invalid %q option %q", decOption, value)
+	}
+
+	valuer, ok := decimalValuer(t, dec)
+	if !ok || f.valuer == nil || f.unmarshaler {
+		return fmt.Errorf("option %q is only supported on numeric fields", decOption)
+	}
+
+	f.valuer = valuer
+	f.encoder = decimalEncoder(t, dec)
+
+	return nil
+}
+
+// resolve determines how values of type t are decoded and encoded. It returns false if t is not
+// supported.
+func (f *fieldPlan) resolve(t reflect.Type, o *options, parents []reflect.Type) (bool, error) {
+	if isRepeated(t) {
+		return f.resolveRepeated(t, o, parents)
+	}
+
+	if isTime(t) {
+		if f.timeLayout == "" {
+			f.timeLayout = time.RFC3339
+		}
+
+		return true, nil
+	}
+
+	f.timeLayout = ""
+
+	if isUnmarshaler(t) {
+		f.unmarshaler = true
+		f.retains = true
+		f.marshaler = isMarshaler(t)
+		f.encoder, f.numeric, _ = fieldEncoder(t)
+
+		return true, nil
+	}
+
+	if valuer, ok := fieldValuer(t); ok {
+		f.valuer = valuer
+		f.retains = isText(t)
+		// blank strings are valid values
+		f.nilIfBlank = t.Kind() == reflect.Ptr && t.Elem().Kind() != reflect.String
+		f.marshaler = isMarshaler(t)
+		f.encoder, f.numeric, _ = fieldEncoder(t)
+
+		return true, nil
+	}
+
+	return f.resolveNested(t, o, parents)
+}
+
+// resolveRepeated determines how the occurrences of the repeated group f of type t are decoded
+// and encoded. It returns false if they are not supported.
+func (f *fieldPlan) resolveRepeated(
+	t reflect.Type, o *options, parents []reflect.Type,
+) (bool, error) {
+	// repeated groups of repeated groups are not supported
+	if isRepeated(t.Elem()) {
+		return false, nil
+	}
+
+	f.elem = &fieldPlan{
+		name:         f.name,
+		endIdx:       -1,
+		formatter:    f.formatter,
+		hasFormatter: f.hasFormatter,
+		timeLayout:   f.timeLayout,
+		element:      true,
+	}
+	f.hasFormatter = false
+	f.timeLayout = ""
+
+	return f.elem.resolve(t.Elem(), o, parents)
+}
+
+// resolveNested compiles the layout of the nested struct field f of type t. It returns false if t
+// is neither a struct nor a pointer to one.
+func (f *fieldPlan) resolveNested(
+	t reflect.Type, o *options, parents []reflect.Type,
+) (bool, error) {
+	st, ptr := structType(t)
+	if st == nil {
+		return false, nil
+	}
+
+	nested, err := compileType(st, o, parents)
+	if err != nil {
+		return false, err
+	}
+
+	f.nested, f.ptr = nested, ptr
+	f.nilIfBlank = ptr
+
+	return true, nil
+}
+
+// applyPacking configures the representation of numeric fields.
+func (f *fieldPlan) applyPacking(tag *fieldTag) error {
+	_, packed := tag.take(packedFlag)
+	_, zoned := tag.take(zonedFlag)
+
+	switch {
+	case !packed && !zoned:
+		return nil
+	case packed && zoned:
+		return fmt.Errorf("options %q and %q are mutually exclusive", packedFlag, zonedFlag)
+	case !f.decodesNumbers():
+		return fmt.Errorf(
+			"options %q and %q are only supported on numeric fields", packedFlag, zonedFlag,
+		)
+	case packed:
+		f.packing = packedDecimal
+		// packed values always fill their field, which is left blank for nil pointers and decoded
+		// back as nil
+		f.numeric = false
+	default:
+		f.packing = zonedDecimal
+	}
+
+	return nil
+}
+
+// decodesNumbers returns true if f is a numeric field decoded by its valuer.
+func (f *fieldPlan) decodesNumbers() bool {
+	return f.numeric && f.valuer != nil && !f.unmarshaler
+}
+
+// resolveOccurs configures the repeated group f of type ft, a field of struct type t.
+func (f *fieldPlan) resolveOccurs(t, ft reflect.Type, tag *fieldTag) error {
+	if ft.Kind() == reflect.Array {
+		f.occurs = ft.Len()
+	}
+
+	if occurs, ok := tag.take(occursOption); ok {
+		err := f.resolveOccursOption(t, ft, occurs)
+		if err != nil {
+			return err
+		}
+	}
+
+	if f.occurs == -1 && f.occursIdx == -1 {
+		return fmt.Errorf("missing %q option", occursOption)
+	}
+
+	return f.resolveElem(tag)
+}
+
+// resolveElem determines the width of the occurrences of the repeated group f, either from the
+// "elem" option or from the width of the field and the fixed number of occurrences.
+func (f *fieldPlan) resolveElem(tag *fieldTag) error {
+	elem, ok := tag.take(elemOption)
+	if !ok {
+		if f.occurs <= 0 || f.endIdx == -1 || (f.endIdx-f.startIdx)%f.occurs != 0 {
+			return fmt.Errorf("missing %q option", elemOption)
+		}
+
+		f.elemWidth = (f.endIdx - f.startIdx) / f.occurs
+
+		return nil
+	}
+
+	width, err := strconv.Atoi(elem)
+	if err != nil || width <= 0 {
+		return fmt.Errorf("invalid %q option %q", elemOption, elem)
+	}
+
+	f.elemWidth = width
+
+	return f.checkOccursWidth()
+}
+
+// checkOccursWidth returns an error if the fixed number of occurrences of the repeated group f
+// does not fit the field.
+func (f *fieldPlan) checkOccursWidth() error {
+	if f.occurs <= 0 || f.endIdx == -1 || f.occurs*f.elemWidth <= f.endIdx-f.startIdx {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%d occurrences of width %d do not fit the field's width %d",
+		f.occurs, f.elemWidth, f.endIdx-f.startIdx,
+	)
+}
+
+// resolveOccursOption applies the "occurs" option of the repeated group f of type ft, a field of
+// struct type t.
+func (f *fieldPlan) resolveOccursOption(t, ft reflect.Type, occurs string) error {
+	if n, err := strconv.Atoi(occurs); err == nil {
+		return f.fixOccurs(ft, n, occurs)
+	}
+
+	cf, ok := t.FieldByName(occurs)
+	if !ok || len(cf.Index) != 1 || cf.Index[0] >= f.index || !isInteger(cf.Type.Kind()) {
+		return fmt.Errorf(
+			"invalid %q option %q: must be an integer or a preceding integer field",
+			occursOption, occurs,
+		)
+	}
+
+	f.occurs = -1
+	f.occursIdx = cf.Index[0]
+
+	return nil
+}
+
+// fixOccurs sets the fixed number of occurrences n of the repeated group f of type ft, given by
+// the "occurs" option.
+func (f *fieldPlan) fixOccurs(ft reflect.Type, n int, occurs string) error {
+	if n < 0 || (ft.Kind() == reflect.Array && n > ft.Len()) {
+		return fmt.Errorf("invalid %q option %q", occursOption, occurs)
+	}
+
+	f.occurs = n
+
+	return nil
+}
+
+// fieldValuer returns the valuer for fields of type t and whether t is supported.
+func fieldValuer(t reflect.Type) (primitiveValuer, bool) {
+	if t == minorUnitsType || (t.Kind() == reflect.Ptr && t.Elem() == minorUnitsType) {
+		return decimalValuer(t, 0)
+	}
+
+	switch t.Kind() { //nolint:exhaustive
+	case reflect.Ptr:
+		valuer, ok := builtinPointers[t.Elem().Kind()]
+
+		return valuer, ok
+	case reflect.Slice:
+		return bytesValuer(t)
+	case reflect.Array:
+		return byteArrayValuer(t)
+	default:
+		valuer, ok := builtin[t.Kind()]
+
+		return valuer, ok
+	}
+}
+
+// structType returns the struct type of t if t is a struct or a pointer to a struct, and whether
+// t is a pointer.
+func structType(t reflect.Type) (reflect.Type, bool) {
+	switch {
+	case t.Kind() == reflect.Struct:
+		return t, false
+	case t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct:
+		return t.Elem(), true
+	default:
+		return nil, false
+	}
+}
+
+// isRepeated returns true if t is a repeated group, ie. a slice or an array of other elements
+// than bytes.
+func isRepeated(t reflect.Type) bool {
+	return (t.Kind() == reflect.Array || t.Kind() == reflect.Slice) &&
+		t.Elem().Kind() != reflect.Uint8
+}
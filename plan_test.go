@@ -0,0 +1,125 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+const benchLine = "BobDolebob.dole@example.com123Grace StreetUnit 123TorontoOntarioM5A1A1true"
+
+type benchContact struct {
+	FirstName    string `strum:"0,3"`
+	LastName     string `strum:"3,7"`
+	Email        string `strum:"7,27"`
+	StreetNumber int    `strum:"27,30"`
+	Street       string `strum:"30,42"`
+	Unit         string `strum:"42,50"`
+	City         string `strum:"50,57"`
+	Province     string `strum:"57,64"`
+	PostalCode   string `strum:"64,70"`
+	Verified     bool   `strum:"70,74"`
+}
+
+func TestUnmarshal_plans(t *testing.T) {
+	t.Run("safe for concurrent use", func(t *testing.T) {
+		var wg sync.WaitGroup
+
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for j := 0; j < 100; j++ {
+					var c benchContact
+
+					err := strum.Unmarshal(benchLine, &c)
+					require.NoError(t, err)
+					require.Equal(t, "bob.dole@example.com", c.Email)
+				}
+			}()
+		}
+
+		wg.Wait()
+	})
+
+	t.Run("layouts depend on the delimiter", func(t *testing.T) {
+		type record struct {
+			Val string `strum:"1-3"`
+		}
+
+		var r record
+
+		err := strum.Unmarshal("abcde", &r, strum.WithDelimiter("-"))
+		require.NoError(t, err)
+		require.Equal(t, "bc", r.Val)
+
+		err = strum.Unmarshal("abcde", &r)
+		require.ErrorContains(t, err, "invalid start index")
+	})
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var c benchContact
+
+		err := strum.Unmarshal(benchLine, &c)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	var c benchContact
+
+	err := strum.Unmarshal(benchLine, &c)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err = strum.Marshal(c)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalBytes(b *testing.B) {
+	line := []byte(benchLine)
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var c benchContact
+
+		err := strum.UnmarshalBytes(line, &c)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
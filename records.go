@@ -0,0 +1,90 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// Record is a value decoded from a line of input.
+type Record[T any] struct {
+	// Line is the number of the line, starting from 1.
+	Line int
+	// Value is the decoded value.
+	Value T
+}
+
+// Records returns an iterator over the values of type T decoded from each line of r, as read by
+// a Decoder. See NumberedRecords.
+//
+//	for txn, err := range strum.Records[Txn](f) {
+//		...
+//	}
+func Records[T any](r io.Reader, opts ...Option) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for rec, err := range NumberedRecords[T](r, opts...) {
+			if !yield(rec.Value, err) {
+				return
+			}
+		}
+	}
+}
+
+// NumberedRecords returns an iterator over the values of type T decoded from each line of r,
+// along with their line numbers. Lines are read as the iteration proceeds, so breaking out of the
+// loop stops reading.
+//
+// A line that cannot be decoded yields an error that indicates its number, and the iteration
+// continues with the next line. Errors on reading r, or on compiling T (see Compile), are yielded
+// once and end the iteration.
+func NumberedRecords[T any](r io.Reader, opts ...Option) iter.Seq2[Record[T], error] {
+	return func(yield func(Record[T], error) bool) {
+		p, err := Compile[T](opts...)
+		if err != nil {
+			yield(Record[T]{}, err)
+
+			return
+		}
+
+		d := NewDecoder(r)
+
+		for {
+			line, text, err := d.read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			if err != nil {
+				yield(Record[T]{Line: line}, err)
+
+				return
+			}
+
+			rec := Record[T]{Line: line}
+
+			err = p.Unmarshal(text, &rec.Value)
+			if err != nil {
+				err = fmt.Errorf("line %d: %w", line, err)
+			}
+
+			if !yield(rec, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+func TestRecords(t *testing.T) { //nolint:funlen
+	t.Run("decodes every line", func(t *testing.T) {
+		var records []decoderRecord
+
+		for r, err := range strum.Records[decoderRecord](strings.NewReader("abc001\r\ndef002")) {
+			require.NoError(t, err)
+
+			records = append(records, r)
+		}
+
+		require.Equal(t, []decoderRecord{{"abc", 1}, {"def", 2}}, records)
+	})
+
+	t.Run("continues after errors", func(t *testing.T) {
+		var errs []error
+
+		for _, err := range strum.Records[decoderRecord](strings.NewReader("abcxyz\ndef002\nx\n")) {
+			errs = append(errs, err)
+		}
+
+		require.Len(t, errs, 3)
+		require.ErrorContains(t, errs[0], "line 1: ")
+		require.ErrorIs(t, errs[0], strum.ErrParse)
+		require.NoError(t, errs[1])
+		require.ErrorContains(t, errs[2], "line 3: ")
+		require.ErrorIs(t, errs[2], strum.ErrOutOfBounds)
+	})
+
+	t.Run("break stops reading", func(t *testing.T) {
+		r := io.MultiReader(
+			strings.NewReader("abc001\n"),
+			&errReader{err: errors.New("test")},
+		)
+
+		for rec, err := range strum.Records[decoderRecord](r) {
+			require.NoError(t, err)
+			require.Equal(t, decoderRecord{"abc", 1}, rec)
+
+			break
+		}
+	})
+
+	t.Run("read errors end the iteration", func(t *testing.T) {
+		expected := errors.New("test")
+		r := io.MultiReader(strings.NewReader("abc001\n"), &errReader{err: expected})
+
+		var errs []error
+
+		for _, err := range strum.Records[decoderRecord](r) {
+			errs = append(errs, err)
+		}
+
+		require.Len(t, errs, 2)
+		require.NoError(t, errs[0])
+		require.ErrorIs(t, errs[1], expected)
+		require.ErrorContains(t, errs[1], "line 2: ")
+	})
+
+	t.Run("compile errors end the iteration", func(t *testing.T) {
+		var errs []error
+
+		for _, err := range strum.Records[int](strings.NewReader("1\n2\n")) {
+			errs = append(errs, err)
+		}
+
+		require.Len(t, errs, 1)
+		require.ErrorIs(t, errs[0], strum.ErrNotStruct)
+	})
+}
+
+func TestNumberedRecords(t *testing.T) {
+	var records []strum.Record[decoderRecord]
+
+	input := strings.NewReader("abc001\nabcxyz\ndef002\n")
+
+	for rec, err := range strum.NumberedRecords[decoderRecord](input) {
+		if err != nil {
+			require.Equal(t, 2, rec.Line)
+
+			continue
+		}
+
+		records = append(records, rec)
+	}
+
+	require.Equal(t, []strum.Record[decoderRecord]{
+		{Line: 1, Value: decoderRecord{"abc", 1}},
+		{Line: 3, Value: decoderRecord{"def", 2}},
+	}, records)
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RecordSet decodes lines of files that interleave several types of records. The type of each
+// line is identified by a discriminator: the substring found between fixed indexes of every line.
+//
+// A RecordSet must not be modified after its first use. It is safe for concurrent use afterwards.
+type RecordSet struct {
+	startIdx int
+	endIdx   int
+	opts     []Option
+	types    map[string]recordType
+	// codePage transcodes discriminators, if selected with WithCodePage.
+	codePage *codePage
+	unit     indexUnit
+}
+
+type recordType struct {
+	t   reflect.Type
+	ptr bool
+}
+
+// NewRecordSet returns a RecordSet whose discriminator is line[startIdx:endIdx]. The given options
+// are applied to every line. Discriminators are transcoded to UTF-8 before being compared to the
+// registered codes if a code page is selected with WithCodePage.
+func NewRecordSet(startIdx, endIdx int, opts ...Option) *RecordSet {
+	options := *defaultOptions
+
+	for i := range opts {
+		opts[i](&options)
+	}
+
+	if options.oneBased {
+		startIdx--
+	}
+
+	return &RecordSet{
+		startIdx: startIdx,
+		endIdx:   endIdx,
+		opts:     opts,
+		types:    make(map[string]recordType),
+		codePage: options.codePage,
+		unit:     options.indexUnit(),
+	}
+}
+
+// Register decodes lines whose discriminator equals code into values of the same type as
+// prototype, which must be either a struct or a pointer to a struct. It returns the RecordSet
+// so that calls can be chained.
+//
+// Register panics if prototype is not a struct or a pointer to a struct, or if code is already
+// registered.
+func (rs *RecordSet) Register(code string, prototype any) *RecordSet {
+	t := reflect.TypeOf(prototype)
+	rt := recordType{t: t}
+
+	if t != nil && t.Kind() == reflect.Ptr {
+		rt = recordType{t: t.Elem(), ptr: true}
+	}
+
+	if rt.t == nil || rt.t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("strum: record %q is not a struct: %v", code, t))
+	}
+
+	if _, ok := rs.types[code]; ok {
+		panic(fmt.Sprintf("strum: record %q is already registered", code))
+	}
+
+	rs.types[code] = rt
+
+	return rs
+}
+
+// Unmarshal decodes line into a new value of the type registered under the line's discriminator
+// (see Unmarshal). The returned value has the same type as the prototype given to Register:
+// either a struct or a pointer to one.
+func (rs *RecordSet) Unmarshal(line string) (any, error) {
+	code, err := rs.unit.slice(line, rs.startIdx, rs.endIdx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid discriminator indexes: %w", err)
+	}
+
+	if rs.codePage != nil {
+		code = rs.codePage.decode(code)
+	}
+
+	rt, ok := rs.types[code]
+	if !ok {
+		return nil, fmt.Errorf("unknown record type %q", code)
+	}
+
+	value := reflect.New(rt.t)
+
+	err = Unmarshal(line, value.Interface(), rs.opts...)
+	if err != nil {
+		return nil, fmt.Errorf("record type %q: %w", code, err)
+	}
+
+	if rt.ptr {
+		return value.Interface(), nil
+	}
+
+	return value.Elem().Interface(), nil
+}
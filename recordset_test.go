@@ -0,0 +1,106 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+type headerRecord struct {
+	Type string `strum:"0,2"`
+	Date string `strum:"2,10"`
+}
+
+type detailRecord struct {
+	Type   string `strum:"0,2"`
+	Amount int    `strum:"2,7"`
+}
+
+type upperRecord struct {
+	Val string `strform:"upper" strum:"2"`
+}
+
+func TestRecordSet(t *testing.T) { //nolint:funlen
+	t.Run("decodes lines into their registered types", func(t *testing.T) {
+		rs := strum.NewRecordSet(0, 2).
+			Register("01", headerRecord{}).
+			Register("02", &detailRecord{})
+
+		header, err := rs.Unmarshal("0120240101")
+		require.NoError(t, err)
+		require.Equal(t, headerRecord{Type: "01", Date: "20240101"}, header)
+
+		detail, err := rs.Unmarshal("0200123")
+		require.NoError(t, err)
+		require.Equal(t, &detailRecord{Type: "02", Amount: 123}, detail)
+	})
+
+	t.Run("applies options", func(t *testing.T) {
+		rs := strum.NewRecordSet(0, 2,
+			strum.WithFormatter("upper", func(s string) (string, error) {
+				return strings.ToUpper(s), nil
+			}),
+		).Register("01", upperRecord{})
+
+		record, err := rs.Unmarshal("01abc")
+		require.NoError(t, err)
+		require.Equal(t, upperRecord{Val: "ABC"}, record)
+	})
+
+	t.Run("error when the discriminator is unknown", func(t *testing.T) {
+		rs := strum.NewRecordSet(0, 2).Register("01", headerRecord{})
+
+		_, err := rs.Unmarshal("0320240101")
+		require.ErrorContains(t, err, `unknown record type "03"`)
+	})
+
+	t.Run("error when the line is shorter than the discriminator", func(t *testing.T) {
+		rs := strum.NewRecordSet(0, 2).Register("01", headerRecord{})
+
+		_, err := rs.Unmarshal("0")
+		require.ErrorContains(t, err, "end index out of bounds")
+	})
+
+	t.Run("error when the record cannot be decoded", func(t *testing.T) {
+		rs := strum.NewRecordSet(0, 2).Register("02", detailRecord{})
+
+		_, err := rs.Unmarshal("02abcde")
+		require.ErrorContains(t, err, `record type "02"`)
+		require.ErrorContains(t, err, `field "Amount"`)
+	})
+
+	t.Run("panics when the prototype is not a struct", func(t *testing.T) {
+		require.Panics(t, func() {
+			strum.NewRecordSet(0, 2).Register("01", "abc")
+		})
+
+		require.Panics(t, func() {
+			strum.NewRecordSet(0, 2).Register("01", nil)
+		})
+	})
+
+	t.Run("panics when the code is already registered", func(t *testing.T) {
+		require.Panics(t, func() {
+			strum.NewRecordSet(0, 2).
+				Register("01", headerRecord{}).
+				Register("01", detailRecord{})
+		})
+	})
+}
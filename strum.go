@@ -19,7 +19,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
-	"strings"
+	"time"
 )
 
 const (
@@ -27,23 +27,46 @@ const (
 	TagName = "strum"
 	// FormatterTagName is the struct tag that identifies the field's Formatter.
 	FormatterTagName = "strform"
+	// TimeLayoutTagName is the struct tag that holds the layout of time.Time fields.
+	TimeLayoutTagName = "strtime"
 	// DefaultDelimiter is the default one used to separate the start and end indexes.
 	DefaultDelimiter = ","
+	// ignoreTag excludes a field from the layout.
+	ignoreTag = "-"
 )
 
 var defaultOptions = &options{
-	delimiter: DefaultDelimiter,
+	delimiter:  DefaultDelimiter,
+	terminator: LF,
+	location:   time.UTC,
 }
 
 type options struct {
-	delimiter  string
-	formatters map[string]Formatter
+	delimiter     string
+	formatters    map[string]Formatter
+	padding       byte
+	justification Justification
+	terminator    string
+	location      *time.Location
+	codePageName  string
+	codePage      *codePage
+	unit          indexUnit
+	oneBased      bool
+	allErrors     bool
+	recordLength  int
+	strictLength  bool
+	workers       int
+	// buffer is the line given to UnmarshalBytes, which substrings may reference.
+	buffer []byte
+	// detached is a copy of buffer, made when the first decoded value would reference it.
+	detached   string
+	aliasBytes bool
 }
 
 // Formatter formats the input string before it is parsed and assigned to the field.
 type Formatter func(string) (string, error)
 
-// Option allows some customization of the Unmarshal process.
+// Option allows some customization of the Unmarshal and Marshal processes.
 type Option func(*options)
 
 // WithDelimiter uses the given delimiter instead of DefaultDelimiter.
@@ -69,118 +92,272 @@ func WithFormatter(name string, f Formatter) Option {
 // Unmarshal decodes strings into structs.
 //
 // If a field is tagged with TagName it assigns the indicated substring, otherwise the field is
-// ignored.
+// ignored, as are fields tagged "-". Unexported fields must not be tagged, except with "-".
 //
 // TagName has the format "startIdx{delimiter}endIdx" where both startIdx and endIdx are
 // integers and are optional, but at least one must be present. {delimiter} is specified by the user
 // (default is DefaultDelimiter). {delimiter} is mandatory unless only startIdx is provided.
-// Errors are raised if startIdx or endIdx exceed the string's bounds.
+// Errors are raised if startIdx or endIdx exceed the string's bounds. Indexes are byte offsets
+// unless WithRuneIndexes or WithDisplayWidthIndexes is given.
+//
+// The indexes may also be given as options (see below): "pos=startIdx{delimiter}len=width", where
+// len is optional. With WithOneBasedIndexes, startIdx is 1-based and endIdx is inclusive, as in
+// most record layout specifications: "15{delimiter}22" and "pos=15{delimiter}len=8" are the same
+// field.
+//
+// Contiguous fields may instead declare only their width with "w=width": such a field starts
+// where the preceding field ends, or at 0 for the first field. "skip=n" leaves n positions
+// before the field, and a tag with "skip" but no "w" is a filler that is not decoded, as are
+// blank (_) fields. See Fields for the resulting indexes.
 //
 // If the field is tagged with FormatterTagName then its substring will be formatted prior to
 // decoding (see the WithFormatter Option).
-func Unmarshal(line string, v any, opts ...Option) error { //nolint:funlen,gocyclo
+//
+// Fields of struct type, or pointers to struct types, are decoded recursively from their
+// substring: the indexes of the nested struct's fields are relative to the start of the outer
+// field. Nil pointers are allocated as needed, unless the field's substring is blank.
+//
+// Slices and arrays of other elements than bytes are repeated groups, akin to COBOL's OCCURS
+// clause: a sequence of consecutive occurrences of the same width, each decoded like a field of
+// the element type. The number of occurrences is given by the "occurs" option, either as an
+// integer or as the name of a preceding integer field, and their width by the "elem" option,
+// e.g. "10,130{delimiter}occurs=10{delimiter}elem=12". Options follow the indexes and are
+// separated by {delimiter}. Arrays have as many occurrences as their length by default, and
+// "elem" may be omitted when the number of occurrences is fixed and endIdx is present.
+// Formatters are applied to each occurrence. []byte and [N]byte fields hold raw bytes.
+//
+// Numeric fields accept the "dec" option, the number of implied decimal places of their values,
+// e.g. "0,12{delimiter}dec=2" decodes "000000012345" as 123.45. Values may have a leading or
+// trailing sign. Integer fields reject values with a fractional part, except for MinorUnits
+// which keep every digit without going through floating point.
+//
+// Numeric fields also accept either the "packed" flag, for values in packed decimal (COMP-3), or
+// the "zoned" flag, for values in zoned decimal with the sign overpunched on the last digit
+// (e.g. "0001234J" is -12341). They are decoded before being formatted and combine with "dec".
+//
+// Fields of type time.Time, or pointers to it, are parsed with the layout given by
+// TimeLayoutTagName (default is time.RFC3339) in the location given by WithLocation (default is
+// time.UTC). Substrings made of only zeros and spaces are decoded as the zero time, or as nil
+// pointers.
+//
+// Named types, e.g. `type Cents int64`, are decoded like their underlying builtin type.
+//
+// Blank substrings of pointer fields, which Marshal writes for nil pointers, are decoded as nil
+// pointers, except for pointers to strings.
+//
+// Optional fields accept the "omitempty" flag, which leaves the field to its zero value, e.g. a
+// nil pointer, when its substring is blank (empty or only spaces), and the "nullif" option, which
+// does so when the substring equals the option's value, e.g. "0,6{delimiter}nullif=000000". The
+// "default" option instead decodes the option's value when the substring is blank, as if it were
+// the field's formatted substring. Marshal encodes zero values of fields with "omitempty" as
+// blanks and those of fields with "nullif" as the option's value.
+//
+// Fields whose type implements FieldUnmarshaler or encoding.TextUnmarshaler, on either value or
+// pointer receivers, decode themselves. These interfaces take precedence over the builtin
+// support for the field's kind.
+//
+// Lines are expected in UTF-8 unless a code page is selected with WithCodePage.
+func Unmarshal(line string, v any, opts ...Option) error {
 	options := *defaultOptions
 
 	for i := range opts {
 		opts[i](&options)
 	}
 
+	return unmarshal(line, v, &options)
+}
+
+// unmarshal decodes line into v, which must be a non-nil pointer to a struct.
+func unmarshal(line string, v any, options *options) error {
+	err := validateCodePage(options)
+	if err != nil {
+		return err
+	}
+
 	value := reflect.ValueOf(v)
 
-	err := validateInput(v, value)
+	err = validateInput(v, value)
 	if err != nil {
 		return err
 	}
 
 	value = value.Elem()
-	t := value.Type()
 
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
-		fv := value.Field(i)
+	p, err := compile(value.Type(), options)
+	if err != nil {
+		return err
+	}
 
-		if !fv.CanSet() {
-			//nolint:godox
-			// TODO should we skip fields we can't set instead of returning an error?
-			return fmt.Errorf("cannot assign any value to field %q", f.Name)
-		}
+	return p.unmarshal(line, value, options)
+}
 
-		tagValue, ok := f.Tag.Lookup(TagName)
-		if !ok {
-			continue
+// unmarshal decodes line into value, a struct of the plan's type.
+func (p *plan) unmarshal(line string, value reflect.Value, o *options) error {
+	if o.strictLength {
+		err := p.checkLength(line, o)
+		if err != nil {
+			return err
 		}
+	}
 
-		var (
-			valuer    primitiveValuer
-			isBuiltin bool
-		)
+	return p.decode(line, value, o)
+}
 
-		switch f.Type.Kind() {
-		case reflect.Ptr:
-			valuer, isBuiltin = builtinPointers[f.Type.Elem().Kind()]
-		case reflect.Slice:
-			if f.Type.Elem().Kind() != reflect.Uint8 {
-				continue
-			}
+func (p *plan) decode(line string, value reflect.Value, o *options) error {
+	var errs FieldErrors
 
-			valuer = func(s string) (reflect.Value, error) {
-				return reflect.ValueOf([]byte(s)), nil
-			}
-			isBuiltin = true
-		default:
-			valuer, isBuiltin = builtin[f.Type.Kind()]
-		}
+	for i := range p.fields {
+		f := &p.fields[i]
 
-		if !isBuiltin {
-			continue
+		rawVal, err := f.substring(line, o)
+		if err == nil {
+			if f.elem != nil {
+				err = f.decodeOccurs(rawVal, value, o)
+			} else {
+				err = f.decode(rawVal, value.Field(f.index), o)
+			}
 		}
 
-		startIdx, endIdx, err := indexes(tagValue, options.delimiter)
 		if err != nil {
-			return fmt.Errorf("format error on field %q: %w", f.Name, err)
-		}
+			errs = append(errs, f.fieldErrors(err, rawVal, f.name)...)
 
-		if endIdx == -1 {
-			endIdx = len(line)
+			if !o.allErrors {
+				return errs[0]
+			}
 		}
+	}
 
-		err = validateIndexes(line, startIdx, endIdx)
-		if err != nil {
-			return fmt.Errorf("invalid indexes on field %q: %w", f.Name, err)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// decode formats the field's substring rawVal and assigns it to fv.
+func (f *fieldPlan) decode(rawVal string, fv reflect.Value, o *options) error {
+	rawVal = f.fromCodePage(rawVal, o)
+
+	if f.blanks != nil {
+		if ok, err := f.decodeBlank(rawVal, fv, o); ok {
+			return err
 		}
+	}
 
-		strVal := line[startIdx:endIdx]
+	if f.nilIfBlank && f.isBlank(rawVal, o) {
+		fv.SetZero()
 
-		formatterName, ok := f.Tag.Lookup(FormatterTagName)
-		if ok {
-			formatter, ok := options.formatters[formatterName]
-			if !ok {
-				return fmt.Errorf("unknown formatter %q on field %q", formatterName, f.Name)
-			}
+		return nil
+	}
 
-			strVal, err = formatter(strVal)
-			if err != nil {
-				return fmt.Errorf("formatter failed on field %q: %w", f.Name, err)
-			}
+	strVal, err := f.unpack(rawVal)
+	if err != nil {
+		return f.parseError(rawVal, err)
+	}
+
+	strVal, err = f.format(strVal, o)
+	if err != nil {
+		return err
+	}
+
+	return f.assign(strVal, rawVal, fv, o)
+}
+
+// assign decodes strVal, the formatted substring rawVal, into fv.
+func (f *fieldPlan) assign(strVal, rawVal string, fv reflect.Value, o *options) error {
+	if f.timeLayout != "" {
+		return f.decodeTime(strVal, fv, o)
+	}
+
+	if f.retains && o.borrows(strVal) {
+		if f.alias(strVal, fv, o) {
+			return nil
 		}
 
-		val, err := valuer(strVal)
-		if err != nil {
-			return fmt.Errorf(
-				"cannot assign value %q to field %q: %w", line[startIdx:endIdx], f.Name, err,
-			)
+		strVal = o.detach(strVal)
+	}
+
+	if f.unmarshaler {
+		return f.unmarshal(strVal, fv)
+	}
+
+	if f.nested != nil {
+		return f.decodeNested(strVal, fv, o)
+	}
+
+	return f.set(strVal, rawVal, fv)
+}
+
+// decodeNested decodes strVal into the nested struct field fv, allocating nil pointers.
+func (f *fieldPlan) decodeNested(strVal string, fv reflect.Value, o *options) error {
+	if f.ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
 		}
 
-		fv.Set(val)
+		fv = fv.Elem()
 	}
 
+	err := f.nested.decode(strVal, fv, o)
+	if err != nil && !f.element {
+		// decodeOccurrence already names the repeated group
+		return wrapError(err, "field %q", f.name)
+	}
+
+	return err
+}
+
+// set sets fv to the value of strVal, the formatted substring rawVal, given by the field's valuer.
+func (f *fieldPlan) set(strVal, rawVal string, fv reflect.Value) error {
+	val, err := f.valuer(strVal)
+	if err != nil {
+		return f.parseError(rawVal, err)
+	}
+
+	// the valuers return builtin types, which named types like `type Cents int64` convert from
+	if val.Type() != fv.Type() {
+		val = val.Convert(fv.Type())
+	}
+
+	fv.Set(val)
+
 	return nil
 }
 
-func indexes(tagValue, delimiter string) (int, int, error) {
-	parts := strings.Split(tagValue, delimiter)
+// substring returns the field's substring of line.
+func (f *fieldPlan) substring(line string, o *options) (string, error) {
+	rawVal, err := o.indexUnit().slice(line, f.startIdx, f.endIdx)
+	if err != nil {
+		return "", fmt.Errorf(
+			"invalid indexes on field %q tagged %s: %w",
+			f.name, tagString(f.field.Tag.Get(TagName)), err,
+		)
+	}
+
+	return rawVal, nil
+}
+
+// format applies the field's Formatter, if any, to strVal.
+func (f *fieldPlan) format(strVal string, o *options) (string, error) {
+	if !f.hasFormatter {
+		return strVal, nil
+	}
+
+	formatter, ok := o.formatters[f.formatter]
+	if !ok {
+		return "", fmt.Errorf("%w %q on field %q", ErrUnknownFormatter, f.formatter, f.name)
+	}
 
-	if len(parts) == 0 || len(parts) > 2 {
+	formatted, err := formatter(strVal)
+	if err != nil {
+		return "", fmt.Errorf("%w on field %q: %w", ErrFormatterFailed, f.name, err)
+	}
+
+	return formatted, nil
+}
+
+func indexes(parts []string, tagValue string) (int, int, error) {
+	if len(parts) > 2 {
 		return -1, -1, fmt.Errorf("invalid strum format: %q", tagValue)
 	}
 
@@ -190,7 +367,7 @@ func indexes(tagValue, delimiter string) (int, int, error) {
 		err      error
 	)
 
-	if parts[0] != "" {
+	if len(parts) > 0 && parts[0] != "" {
 		startIdx, err = strconv.Atoi(parts[0])
 		if err != nil {
 			return -1, -1, fmt.Errorf("invalid start index %q: %w", parts[0], err)
@@ -209,31 +386,34 @@ func indexes(tagValue, delimiter string) (int, int, error) {
 
 func validateInput(v any, value reflect.Value) error {
 	if value.Kind() != reflect.Ptr {
-		return fmt.Errorf("not a pointer: %s", reflect.ValueOf(v).Kind())
+		return fmt.Errorf("%w: %s", ErrNotPointer, reflect.ValueOf(v).Kind())
 	}
 
 	if value.IsNil() {
-		return errors.New("nil pointer")
+		return ErrNilPointer
 	}
 
 	if value.Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("not a struct: %s", reflect.ValueOf(v).Kind())
+		return fmt.Errorf("%w: %s", ErrNotStruct, reflect.ValueOf(v).Kind())
 	}
 
 	return nil
 }
 
-func validateIndexes(line string, startIdx, endIdx int) error {
-	if startIdx < 0 || startIdx > len(line) {
-		return errors.New("start index out of bounds")
+// validateIndexes checks startIdx and endIdx against a line of the given length in unit u.
+func validateIndexes(length int, u indexUnit, startIdx, endIdx int) error {
+	if startIdx < 0 || startIdx > length {
+		return fmt.Errorf(
+			"start index %w: %d not within %d %s", ErrOutOfBounds, startIdx, length, u,
+		)
 	}
 
-	if endIdx < 0 || endIdx > len(line) {
-		return errors.New("end index out of bounds")
+	if endIdx < 0 || endIdx > length {
+		return fmt.Errorf("end index %w: %d not within %d %s", ErrOutOfBounds, endIdx, length, u)
 	}
 
 	if endIdx < startIdx {
-		return errors.New(`end index must be greater or equal to start index`)
+		return classify(ErrInvalidTag, errors.New(`end index must be greater or equal to start index`))
 	}
 
 	return nil
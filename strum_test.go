@@ -17,6 +17,7 @@ package strum_test
 import (
 	"errors"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -180,14 +181,54 @@ func TestUnmarshal_indexes(t *testing.T) { //nolint:funlen
 		require.Empty(t, test.Val)
 	})
 
-	t.Run("error if field is not exported", func(t *testing.T) {
+	t.Run("ignores unexported fields without struct tag", func(t *testing.T) {
 		test := &struct {
-			val string `json:"val"`
+			mu    sync.Mutex
+			cache map[string]string
+			Val   string `strum:"0"`
 		}{}
 
 		err := strum.Unmarshal("a", test)
-		require.ErrorContains(t, err, "cannot assign any value to field")
+		require.NoError(t, err)
+		require.Equal(t, "a", test.Val)
+		require.Nil(t, test.cache)
+	})
+
+	t.Run("ignores fields tagged -", func(t *testing.T) {
+		test := &struct {
+			Val     string `strum:"0,1"`
+			Derived string `strum:"-"`
+			hidden  string `strum:"-"`
+		}{Derived: "keep"}
+
+		err := strum.Unmarshal("ab", test)
+		require.NoError(t, err)
+		require.Equal(t, "a", test.Val)
+		require.Equal(t, "keep", test.Derived)
+		require.Empty(t, test.hidden)
+
+		line, err := strum.Marshal(test)
+		require.NoError(t, err)
+		require.Equal(t, "a", line)
+	})
+
+	t.Run("error if unexported field is tagged", func(t *testing.T) {
+		test := &struct {
+			val string `strum:"0"`
+		}{}
+
+		err := strum.Unmarshal("a", test)
+		require.ErrorIs(t, err, strum.ErrInvalidLayout)
+		require.EqualError(t, err,
+			`unexported field "val" cannot be tagged strum:"0": export it or tag it strum:"-"`)
 		require.Empty(t, test.val)
+
+		_, err = strum.Compile[struct {
+			Nested struct {
+				val string `strum:"0"`
+			} `strum:"0"`
+		}]()
+		require.ErrorIs(t, err, strum.ErrInvalidLayout)
 	})
 }
 
@@ -483,6 +524,24 @@ func TestUnmarshal_builtin(t *testing.T) { //nolint:funlen,maintidx
 		require.NoError(t, err)
 		require.Equal(t, []byte("abc"), test.Val)
 	})
+
+	t.Run("[N]byte", func(t *testing.T) {
+		test := &struct {
+			Val   [4]byte `strum:"0,4"`
+			Short [4]byte `strum:"4,6"`
+		}{}
+
+		err := strum.Unmarshal("abcdef", test)
+		require.NoError(t, err)
+		require.Equal(t, [4]byte{'a', 'b', 'c', 'd'}, test.Val)
+		require.Equal(t, [4]byte{'e', 'f'}, test.Short)
+
+		err = strum.Unmarshal("abcde", &struct {
+			Val [4]byte `strum:"0,5"`
+		}{})
+		require.ErrorIs(t, err, strum.ErrParse)
+		require.ErrorContains(t, err, "5 bytes do not fit [4]uint8")
+	})
 }
 
 func TestUnmarshal_formatter(t *testing.T) {
@@ -525,3 +584,147 @@ func TestUnmarshal_delimiter(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "bc", test.Val)
 }
+
+type address struct {
+	Number int    `strum:"0,3"`
+	Street string `strum:"3,8"`
+}
+
+func TestUnmarshal_nested(t *testing.T) { //nolint:funlen
+	t.Run("decodes nested structs relative to the outer field", func(t *testing.T) {
+		test := &struct {
+			Name string  `strum:"0,3"`
+			Home address `strum:"3,11"`
+			Work address `strum:"11,19"`
+		}{}
+
+		err := strum.Unmarshal("Bob123Main 456Queen", test)
+		require.NoError(t, err)
+		require.Equal(t, "Bob", test.Name)
+		require.Equal(t, address{123, "Main "}, test.Home)
+		require.Equal(t, address{456, "Queen"}, test.Work)
+	})
+
+	t.Run("allocates nil pointers to structs", func(t *testing.T) {
+		test := &struct {
+			Home *address `strum:"3"`
+		}{}
+
+		err := strum.Unmarshal("Bob123Main ", test)
+		require.NoError(t, err)
+		require.Equal(t, &address{123, "Main "}, test.Home)
+	})
+
+	t.Run("ignores nested structs without struct tag", func(t *testing.T) {
+		test := &struct {
+			Home address
+		}{}
+
+		err := strum.Unmarshal("123Main ", test)
+		require.NoError(t, err)
+		require.Empty(t, test.Home)
+	})
+
+	t.Run("nested indexes are bound by the outer field", func(t *testing.T) {
+		test := &struct {
+			Home address `strum:"0,5"`
+		}{}
+
+		err := strum.Unmarshal("123Main ", test)
+		require.ErrorContains(t, err, `field "Home"`)
+		require.ErrorContains(t, err, `invalid indexes on field "Street"`)
+	})
+
+	t.Run("error when nested fields cannot be decoded", func(t *testing.T) {
+		test := &struct {
+			Home address `strum:"0"`
+		}{}
+
+		err := strum.Unmarshal("abcMain ", test)
+		require.ErrorContains(t, err, `field "Home"`)
+		require.ErrorContains(t, err, `cannot assign value "abc" to field "Number"`)
+	})
+
+	t.Run("error when nested tags are invalid", func(t *testing.T) {
+		test := &struct {
+			Nested struct {
+				Val string `strum:"invalid"`
+			} `strum:"0"`
+		}{}
+
+		err := strum.Unmarshal("abc", test)
+		require.ErrorContains(t, err, `field "Nested"`)
+		require.ErrorContains(t, err, "invalid start index")
+	})
+
+	t.Run("error when layout is recursive", func(t *testing.T) {
+		err := strum.Unmarshal("abc", &recursive{})
+		require.ErrorContains(t, err, "recursive layout")
+	})
+}
+
+type recursive struct {
+	Next *recursive `strum:"1"`
+}
+
+type (
+	mcc     string
+	cents   int64
+	rate    float64
+	flag    bool
+	payload []byte
+)
+
+func TestUnmarshal_namedTypes(t *testing.T) { //nolint:funlen
+	type record struct {
+		MCC     mcc     `strum:"0,4"`
+		Amount  cents   `strum:"4,10"`
+		Rate    rate    `strum:"10,14"`
+		Active  flag    `strum:"14,18"`
+		Data    payload `strum:"18,21"`
+		Ref     *mcc    `strum:"21,23"`
+		Balance *cents  `strum:"23,26"`
+		Codes   [2]mcc  `strum:"26,30"`
+		Packed  cents   `strum:"30,32,packed"`
+	}
+
+	t.Run("converts to the field's type", func(t *testing.T) {
+		test := &record{}
+
+		err := strum.Unmarshal("54110012500.25trueabcXY042AABB\x12\x3c", test)
+		require.NoError(t, err)
+		require.Equal(t, mcc("5411"), test.MCC)
+		require.Equal(t, cents(1250), test.Amount)
+		require.InDelta(t, 0.25, float64(test.Rate), 1e-9)
+		require.Equal(t, flag(true), test.Active)
+		require.Equal(t, payload("abc"), test.Data)
+		require.Equal(t, mcc("XY"), *test.Ref)
+		require.Equal(t, cents(42), *test.Balance)
+		require.Equal(t, [2]mcc{"AA", "BB"}, test.Codes)
+		require.Equal(t, cents(123), test.Packed)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		line := "54110012500.25trueabcXY042AABB\x12\x3c"
+		test := &record{}
+
+		require.NoError(t, strum.Unmarshal(line, test))
+
+		test.Rate = 0.5
+
+		out, err := strum.Marshal(test)
+		require.NoError(t, err)
+
+		again := &record{}
+		require.NoError(t, strum.Unmarshal(out, again))
+		require.Equal(t, test, again)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		err := strum.Unmarshal("abcd", &struct {
+			Amount cents `strum:"0,4"`
+		}{})
+		require.ErrorIs(t, err, strum.ErrParse)
+		require.ErrorContains(t, err, `cannot assign value "abcd" to field "Amount"`)
+	})
+}
@@ -0,0 +1,354 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// occursOption is the number of occurrences of a repeated group: either an integer or the
+	// name of a preceding integer field.
+	occursOption = "occurs"
+	// elemOption is the width of each occurrence of a repeated group.
+	elemOption = "elem"
+	// decOption is the number of implied decimal places of numeric fields.
+	decOption = "dec"
+	// packedFlag decodes numeric fields from packed decimal (COMP-3).
+	packedFlag = "packed"
+	// zonedFlag decodes numeric fields from zoned decimal with sign overpunch.
+	zonedFlag = "zoned"
+	// posOption is the start index of the field, an alternative to the positional indexes.
+	posOption = "pos"
+	// lenOption is the width of the field, whose start index is given by posOption.
+	lenOption = "len"
+	// widthOption is the width of a field that starts where the preceding field ends.
+	widthOption = "w"
+	// skipOption is the number of positions between the preceding field and the field.
+	skipOption = "skip"
+	// recordLengthOption is the length of the records described by a struct, declared on one of
+	// its blank fields.
+	recordLengthOption = "reclen"
+	// omitEmptyFlag decodes blank substrings as the field's zero value.
+	omitEmptyFlag = "omitempty"
+	// nullIfOption is the substring decoded as the field's zero value.
+	nullIfOption = "nullif"
+	// defaultOption is the value decoded from blank substrings.
+	defaultOption = "default"
+)
+
+// tagOptions are the options accepted in TagName after the indexes, and whether they take a
+// value. Options without values are flags.
+var tagOptions = map[string]bool{
+	occursOption: true,
+	elemOption:   true,
+	decOption:    true,
+	packedFlag:   false,
+	zonedFlag:    false,
+	posOption:    true,
+	lenOption:    true,
+	widthOption:  true,
+	skipOption:   true,
+
+	recordLengthOption: true,
+	omitEmptyFlag:      false,
+	nullIfOption:       true,
+	defaultOption:      true,
+}
+
+// fieldTag is the parsed value of a field's TagName.
+type fieldTag struct {
+	startIdx int
+	// endIdx is -1 if absent.
+	endIdx int
+	// filler is true if the tag only reserves space in sequential layouts.
+	filler bool
+	// skip is the number of positions left before the field in sequential layouts.
+	skip int
+	// recordLength is the length of the records declared by the tag, or 0.
+	recordLength int
+	options      map[string]string
+}
+
+// parseTag parses TagName values of the form "startIdx{delimiter}endIdx{delimiter}options...",
+// where options have either the form "key=value" or "flag" and are separated by {delimiter}.
+// The indexes may be replaced by the "pos" and "len" options, or by the "w" and "skip" options
+// which place the field after cursor, the end index of the preceding field. If oneBased is true,
+// start indexes are 1-based and end indexes are inclusive.
+func parseTag(tagValue, delimiter string, oneBased bool, cursor int) (fieldTag, error) {
+	parts := strings.Split(tagValue, delimiter)
+	positional := positionalParts(parts)
+
+	startIdx, endIdx, err := indexes(parts[:positional], tagValue)
+	if err != nil {
+		return fieldTag{}, err
+	}
+
+	tag := fieldTag{
+		startIdx: startIdx,
+		endIdx:   endIdx,
+	}
+
+	err = tag.parseOptions(parts[positional:], tagValue)
+	if err != nil {
+		return fieldTag{}, err
+	}
+
+	if oneBased && positional > 0 && parts[0] == "" {
+		// an omitted start index is the first position of the line
+		tag.startIdx = 1
+	}
+
+	err = tag.position(positional > 0, oneBased, cursor)
+	if err != nil {
+		return fieldTag{}, err
+	}
+
+	return tag, nil
+}
+
+// positionalParts returns the number of parts of a TagName value that precede the options.
+func positionalParts(parts []string) int {
+	for i := range parts {
+		key, _, hasValue := strings.Cut(parts[i], "=")
+		if _, ok := tagOptions[key]; hasValue || ok {
+			return i
+		}
+	}
+
+	return len(parts)
+}
+
+// parseOptions parses the options of the TagName value tagValue into the tag.
+func (t *fieldTag) parseOptions(parts []string, tagValue string) error {
+	for _, part := range parts {
+		key, value, hasValue := strings.Cut(part, "=")
+		if wantsValue, ok := tagOptions[key]; !ok || hasValue != wantsValue {
+			return fmt.Errorf("invalid option %q in %q", part, tagValue)
+		}
+
+		if _, ok := t.options[key]; ok {
+			return fmt.Errorf("duplicate option %q in %q", key, tagValue)
+		}
+
+		if t.options == nil {
+			t.options = make(map[string]string)
+		}
+
+		t.options[key] = value
+	}
+
+	return nil
+}
+
+// position applies the "pos", "len", "w" and "skip" options, and converts 1-based indexes if
+// oneBased is true. hasIndexes is true if the tag starts with positional indexes.
+func (t *fieldTag) position(hasIndexes, oneBased bool, cursor int) error {
+	if _, ok := t.options[recordLengthOption]; ok {
+		return t.declareRecordLength(hasIndexes)
+	}
+
+	if t.isSequential() {
+		return t.sequential(hasIndexes, cursor)
+	}
+
+	hasLen, err := t.absolute(hasIndexes)
+	if err != nil {
+		return err
+	}
+
+	if oneBased {
+		err = t.zeroBased(hasLen)
+		if err != nil {
+			return err
+		}
+	}
+
+	return t.checkRange()
+}
+
+// absolute applies the "pos" and "len" options. It returns true if the tag has the "len" option.
+func (t *fieldTag) absolute(hasIndexes bool) (bool, error) {
+	pos, hasPos, err := t.takeInt(posOption)
+	if err != nil {
+		return false, err
+	}
+
+	length, hasLen, err := t.takeInt(lenOption)
+	if err != nil {
+		return false, err
+	}
+
+	err = checkAbsolute(hasIndexes, hasPos, hasLen)
+	if err != nil {
+		return false, err
+	}
+
+	if hasPos {
+		t.startIdx, t.endIdx = pos, -1
+	}
+
+	if hasLen {
+		t.endIdx = pos + length
+	}
+
+	return hasLen, nil
+}
+
+// checkAbsolute rejects the "pos" option when combined with indexes and the "len" option
+// without "pos".
+func checkAbsolute(hasIndexes, hasPos, hasLen bool) error {
+	switch {
+	case hasPos && hasIndexes:
+		return fmt.Errorf("option %q cannot be combined with indexes", posOption)
+	case hasLen && !hasPos:
+		return fmt.Errorf("option %q requires option %q", lenOption, posOption)
+	default:
+		return nil
+	}
+}
+
+// zeroBased converts 1-based indexes, where the end index is inclusive unless it comes from the
+// "len" option, to 0-based indexes. Inclusive ranges span at least one position.
+func (t *fieldTag) zeroBased(hasLen bool) error {
+	if t.startIdx < 1 {
+		return fmt.Errorf("start index %d must be at least 1", t.startIdx)
+	}
+
+	if !hasLen && t.endIdx >= 0 && t.endIdx < t.startIdx {
+		return fmt.Errorf(
+			"end index must be greater or equal to start index: %d < %d", t.endIdx, t.startIdx,
+		)
+	}
+
+	t.startIdx--
+
+	if hasLen {
+		t.endIdx--
+	}
+
+	return nil
+}
+
+// checkRange rejects indexes that no line can satisfy.
+func (t *fieldTag) checkRange() error {
+	switch {
+	case t.startIdx < 0:
+		return fmt.Errorf("start index %d must not be negative", t.startIdx)
+	case t.endIdx < -1:
+		return fmt.Errorf("end index %d must not be negative", t.endIdx)
+	case t.endIdx != -1 && t.endIdx < t.startIdx:
+		return fmt.Errorf(
+			"end index must be greater or equal to start index: %d < %d", t.endIdx, t.startIdx,
+		)
+	default:
+		return nil
+	}
+}
+
+// declareRecordLength applies the "reclen" option, which stands alone.
+func (t *fieldTag) declareRecordLength(hasIndexes bool) error {
+	length, _, err := t.takeInt(recordLengthOption)
+	if err != nil {
+		return err
+	}
+
+	if hasIndexes || len(t.options) > 0 {
+		return fmt.Errorf("option %q cannot be combined with indexes or options", recordLengthOption)
+	}
+
+	if length == 0 {
+		return fmt.Errorf("invalid %q option \"0\"", recordLengthOption)
+	}
+
+	t.recordLength = length
+
+	return nil
+}
+
+// isSequential returns true if the tag has the "w" or "skip" option.
+func (t *fieldTag) isSequential() bool {
+	_, hasWidth := t.options[widthOption]
+	_, hasSkip := t.options[skipOption]
+
+	return hasWidth || hasSkip
+}
+
+// sequential applies the "w" and "skip" options, which place the field after cursor, the end
+// index of the preceding field. Fields without a width are fillers.
+func (t *fieldTag) sequential(hasIndexes bool, cursor int) error {
+	width, hasWidth, err := t.takeInt(widthOption)
+	if err != nil {
+		return err
+	}
+
+	skip, _, err := t.takeInt(skipOption)
+	if err != nil {
+		return err
+	}
+
+	_, hasPos := t.options[posOption]
+	_, hasLen := t.options[lenOption]
+
+	switch {
+	case hasIndexes || hasPos || hasLen:
+		return fmt.Errorf(
+			"options %q and %q cannot be combined with indexes", widthOption, skipOption,
+		)
+	case cursor == -1:
+		return fmt.Errorf(
+			"options %q and %q cannot follow a field that extends to the end of the line",
+			widthOption, skipOption,
+		)
+	}
+
+	t.skip = skip
+	t.startIdx = cursor + skip
+	t.endIdx = t.startIdx + width
+	t.filler = !hasWidth
+
+	return nil
+}
+
+// take returns the value of the given option and removes it from the tag.
+func (t *fieldTag) take(key string) (string, bool) {
+	value, ok := t.options[key]
+	delete(t.options, key)
+
+	return value, ok
+}
+
+// takeInt returns the value of the given option, which must be a non-negative integer, and
+// removes it from the tag.
+func (t *fieldTag) takeInt(key string) (int, bool, error) {
+	value, ok := t.take(key)
+	if !ok {
+		return 0, false, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, false, fmt.Errorf("invalid %q option %q", key, value)
+	}
+
+	return n, true, nil
+}
+
+// tagString returns the given TagName value as written in struct tags, for error messages.
+func tagString(tagValue string) string {
+	return TagName + ":" + strconv.Quote(tagValue)
+}
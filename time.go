@@ -0,0 +1,77 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// isTime returns true if t is time.Time or a pointer to one.
+func isTime(t reflect.Type) bool {
+	return t == timeType || (t.Kind() == reflect.Ptr && t.Elem() == timeType)
+}
+
+// WithLocation interprets times without time zone information in the given location instead
+// of time.UTC. Marshal formats times in this location.
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.location = loc
+	}
+}
+
+// decodeTime parses s into fv, a time.Time or a pointer to one.
+func (f *fieldPlan) decodeTime(s string, fv reflect.Value, o *options) error {
+	if strings.Trim(s, "0 ") == "" {
+		fv.SetZero()
+
+		return nil
+	}
+
+	t, err := time.ParseInLocation(f.timeLayout, s, o.location)
+	if err != nil {
+		return f.parseError(s, err)
+	}
+
+	if fv.Kind() == reflect.Ptr {
+		fv.Set(reflect.ValueOf(&t))
+	} else {
+		fv.Set(reflect.ValueOf(t))
+	}
+
+	return nil
+}
+
+// encodeTime formats fv, a time.Time or a pointer to one. Nil pointers and zero times are
+// encoded as empty values.
+func encodeTime(fv reflect.Value, layout string, o *options) string {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return ""
+		}
+
+		fv = fv.Elem()
+	}
+
+	t := fv.Interface().(time.Time) //nolint:forcetypeassert
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.In(o.location).Format(layout)
+}
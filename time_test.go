@@ -0,0 +1,129 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+func TestUnmarshal_time(t *testing.T) { //nolint:funlen
+	t.Run("parses with the given layout in UTC", func(t *testing.T) {
+		test := &struct {
+			Val time.Time `strtime:"20060102150405" strum:"0,14"`
+		}{}
+
+		err := strum.Unmarshal("20240131235959", test)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC), test.Val)
+	})
+
+	t.Run("defaults to RFC3339", func(t *testing.T) {
+		test := &struct {
+			Val time.Time `strum:"0"`
+		}{}
+
+		err := strum.Unmarshal("2024-01-31T23:59:59-05:00", test)
+		require.NoError(t, err)
+		require.True(t, time.Date(2024, 2, 1, 4, 59, 59, 0, time.UTC).Equal(test.Val))
+	})
+
+	t.Run("pointers", func(t *testing.T) {
+		test := &struct {
+			Val *time.Time `strtime:"060102" strum:"0,6"`
+		}{}
+
+		err := strum.Unmarshal("240131", test)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), *test.Val)
+	})
+
+	t.Run("default location", func(t *testing.T) {
+		loc := time.FixedZone("EST", -5*60*60)
+
+		test := &struct {
+			Val time.Time `strtime:"20060102" strum:"0,8"`
+		}{}
+
+		err := strum.Unmarshal("20240131", test, strum.WithLocation(loc))
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, loc), test.Val)
+	})
+
+	t.Run("all-zero and all-blank fields are zero times or nil pointers", func(t *testing.T) {
+		test := &struct {
+			Zero     time.Time  `strtime:"20060102" strum:"0,8"`
+			Blank    time.Time  `strtime:"20060102" strum:"8,16"`
+			ZeroPtr  *time.Time `strtime:"20060102" strum:"0,8"`
+			BlankPtr *time.Time `strtime:"20060102" strum:"8,16"`
+		}{}
+
+		err := strum.Unmarshal("00000000        ", test)
+		require.NoError(t, err)
+		require.True(t, test.Zero.IsZero())
+		require.True(t, test.Blank.IsZero())
+		require.Nil(t, test.ZeroPtr)
+		require.Nil(t, test.BlankPtr)
+	})
+
+	t.Run("error when the time cannot be parsed", func(t *testing.T) {
+		test := &struct {
+			Val time.Time `strtime:"20060102" strum:"0,8"`
+		}{}
+
+		err := strum.Unmarshal("20241350", test)
+		require.ErrorContains(t, err, `cannot assign value "20241350" to field "Val"`)
+	})
+}
+
+func TestMarshal_time(t *testing.T) {
+	loc := time.FixedZone("EST", -5*60*60)
+
+	type record struct {
+		Date    time.Time  `strtime:"20060102" strum:"0,8"`
+		Time    *time.Time `strtime:"150405" strum:"8,14"`
+		Missing *time.Time `strtime:"150405" strum:"14,20"`
+		Zero    time.Time  `strtime:"20060102" strum:"20,28"`
+	}
+
+	t.Run("round trips", func(t *testing.T) {
+		ts := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+		line, err := strum.Marshal(record{Date: ts, Time: &ts})
+		require.NoError(t, err)
+		require.Equal(t, "20240131235959              ", line)
+
+		var actual record
+
+		err = strum.Unmarshal(line, &actual)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), actual.Date)
+		require.Equal(t, time.Date(0, 1, 1, 23, 59, 59, 0, time.UTC), *actual.Time)
+		require.Nil(t, actual.Missing)
+		require.True(t, actual.Zero.IsZero())
+	})
+
+	t.Run("formats in the default location", func(t *testing.T) {
+		ts := time.Date(2024, 2, 1, 1, 0, 0, 0, time.UTC)
+
+		line, err := strum.Marshal(record{Date: ts}, strum.WithLocation(loc))
+		require.NoError(t, err)
+		require.Equal(t, "20240131", line[:8])
+	})
+}
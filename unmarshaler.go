@@ -0,0 +1,124 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"encoding"
+	"reflect"
+)
+
+// Field describes the struct field being decoded or encoded.
+type Field struct {
+	// Name is the name of the struct field.
+	Name string
+	// StartIdx is the 0-based start index of the field, declared by its TagName or computed from
+	// the widths of the preceding fields.
+	StartIdx int
+	// EndIdx is the exclusive end index of the field, or -1 if it extends to the end of the line.
+	EndIdx int
+	// Tag is the field's complete struct tag.
+	Tag reflect.StructTag
+}
+
+// FieldUnmarshaler is implemented by types that can decode themselves from their field's
+// substring.
+type FieldUnmarshaler interface {
+	// UnmarshalStrum decodes s, the field's substring after formatting.
+	UnmarshalStrum(s string, f Field) error
+}
+
+// FieldMarshaler is implemented by types that can encode themselves into their field's slot.
+type FieldMarshaler interface {
+	// MarshalStrum returns the field's value. It is padded by Marshal if it is narrower than the
+	// field.
+	MarshalStrum(f Field) (string, error)
+}
+
+var (
+	fieldUnmarshalerType = reflect.TypeFor[FieldUnmarshaler]()
+	textUnmarshalerType  = reflect.TypeFor[encoding.TextUnmarshaler]()
+	fieldMarshalerType   = reflect.TypeFor[FieldMarshaler]()
+	textMarshalerType    = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// isUnmarshaler returns true if fields of type t decode themselves.
+func isUnmarshaler(t reflect.Type) bool {
+	return implements(t, fieldUnmarshalerType) || implements(t, textUnmarshalerType)
+}
+
+// isMarshaler returns true if fields of type t encode themselves.
+func isMarshaler(t reflect.Type) bool {
+	return implements(t, fieldMarshalerType) || implements(t, textMarshalerType)
+}
+
+// implements returns true if t implements iface on either value or pointer receivers. Pointer
+// types are checked as is.
+func implements(t, iface reflect.Type) bool {
+	if t.Kind() != reflect.Ptr {
+		t = reflect.PointerTo(t)
+	}
+
+	return t.Implements(iface)
+}
+
+// unmarshal decodes s into fv through its unmarshaler, allocating nil pointers as needed.
+// FieldUnmarshaler takes precedence over encoding.TextUnmarshaler.
+func (f *fieldPlan) unmarshal(s string, fv reflect.Value) error {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+	} else {
+		fv = fv.Addr()
+	}
+
+	var err error
+
+	switch u := fv.Interface().(type) {
+	case FieldUnmarshaler:
+		err = u.UnmarshalStrum(s, f.field)
+	case encoding.TextUnmarshaler:
+		err = u.UnmarshalText([]byte(s))
+	}
+
+	if err != nil {
+		return f.parseError(s, err)
+	}
+
+	return nil
+}
+
+// marshal encodes fv through its marshaler. fv must be addressable. Nil pointers are encoded as
+// empty values. FieldMarshaler takes precedence over encoding.TextMarshaler.
+func (f *fieldPlan) marshal(fv reflect.Value) (string, error) {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return "", nil
+		}
+	} else {
+		fv = fv.Addr()
+	}
+
+	switch m := fv.Interface().(type) {
+	case FieldMarshaler:
+		return m.MarshalStrum(f.field)
+	case encoding.TextMarshaler:
+		b, err := m.MarshalText()
+
+		return string(b), err
+	default:
+		return "", nil
+	}
+}
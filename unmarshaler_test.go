@@ -0,0 +1,209 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+// currency implements encoding.TextUnmarshaler and encoding.TextMarshaler.
+type currency string
+
+func (c *currency) UnmarshalText(text []byte) error {
+	if len(text) != 3 {
+		return errors.New("invalid currency")
+	}
+
+	*c = currency(strings.ToUpper(string(text)))
+
+	return nil
+}
+
+func (c currency) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(string(c))), nil
+}
+
+// brand implements FieldUnmarshaler, FieldMarshaler and encoding.TextUnmarshaler.
+type brand struct {
+	Name  string
+	Field strum.Field
+}
+
+func (b *brand) UnmarshalStrum(s string, f strum.Field) error {
+	switch s {
+	case "V":
+		b.Name = "visa"
+	case "M":
+		b.Name = "mastercard"
+	default:
+		return errors.New("unknown brand")
+	}
+
+	b.Field = f
+
+	return nil
+}
+
+func (b *brand) MarshalStrum(strum.Field) (string, error) {
+	return strings.ToUpper(b.Name[:1]), nil
+}
+
+func (*brand) UnmarshalText([]byte) error {
+	return errors.New("UnmarshalStrum must take precedence")
+}
+
+// code only implements encoding.TextUnmarshaler.
+type code struct {
+	Val string
+}
+
+func (c *code) UnmarshalText(text []byte) error {
+	c.Val = string(text)
+
+	return nil
+}
+
+func TestUnmarshal_unmarshalers(t *testing.T) { //nolint:funlen
+	t.Run("encoding.TextUnmarshaler takes precedence over builtin kinds", func(t *testing.T) {
+		test := &struct {
+			Val currency `strum:"0,3"`
+		}{}
+
+		err := strum.Unmarshal("usd", test)
+		require.NoError(t, err)
+		require.Equal(t, currency("USD"), test.Val)
+	})
+
+	t.Run("FieldUnmarshaler takes precedence over encoding.TextUnmarshaler", func(t *testing.T) {
+		test := &struct {
+			Val brand `custom:"value" strum:"1,2"`
+		}{}
+
+		err := strum.Unmarshal("_V", test)
+		require.NoError(t, err)
+		require.Equal(t, "visa", test.Val.Name)
+		require.Equal(t, "Val", test.Val.Field.Name)
+		require.Equal(t, 1, test.Val.Field.StartIdx)
+		require.Equal(t, 2, test.Val.Field.EndIdx)
+		require.Equal(t, "value", test.Val.Field.Tag.Get("custom"))
+	})
+
+	t.Run("allocates nil pointers", func(t *testing.T) {
+		test := &struct {
+			Brand    *brand    `strum:"0,1"`
+			Currency *currency `strum:"1,4"`
+		}{}
+
+		err := strum.Unmarshal("Musd", test)
+		require.NoError(t, err)
+		require.Equal(t, "mastercard", test.Brand.Name)
+		require.Equal(t, currency("USD"), *test.Currency)
+	})
+
+	t.Run("occurrences decode themselves", func(t *testing.T) {
+		test := &struct {
+			Val []currency `strum:"0,6,occurs=2"`
+		}{}
+
+		err := strum.Unmarshal("usdcad", test)
+		require.NoError(t, err)
+		require.Equal(t, []currency{"USD", "CAD"}, test.Val)
+	})
+
+	t.Run("structs decode themselves instead of being nested", func(t *testing.T) {
+		test := &struct {
+			Val code `strum:"0"`
+		}{}
+
+		err := strum.Unmarshal("abc", test)
+		require.NoError(t, err)
+		require.Equal(t, "abc", test.Val.Val)
+	})
+
+	t.Run("error when the unmarshaler fails", func(t *testing.T) {
+		test := &struct {
+			Val brand `strum:"0,1"`
+		}{}
+
+		err := strum.Unmarshal("X", test)
+		require.ErrorContains(t, err, `cannot assign value "X" to field "Val": unknown brand`)
+	})
+}
+
+func TestMarshal_marshalers(t *testing.T) {
+	t.Run("round trips", func(t *testing.T) {
+		type record struct {
+			Brand    brand     `strum:"0,1"`
+			Currency *currency `strum:"1,4"`
+		}
+
+		usd := currency("USD")
+
+		line, err := strum.Marshal(record{Brand: brand{Name: "visa"}, Currency: &usd})
+		require.NoError(t, err)
+		require.Equal(t, "Vusd", line)
+
+		var actual record
+
+		err = strum.Unmarshal(line, &actual)
+		require.NoError(t, err)
+		require.Equal(t, "visa", actual.Brand.Name)
+		require.Equal(t, usd, *actual.Currency)
+	})
+
+	t.Run("writes nil pointers as empty values", func(t *testing.T) {
+		test := struct {
+			Val *brand `strum:"0,1"`
+		}{}
+
+		line, err := strum.Marshal(test)
+		require.NoError(t, err)
+		require.Equal(t, " ", line)
+	})
+
+	t.Run("error when the type cannot encode itself", func(t *testing.T) {
+		test := struct {
+			Val code `strum:"0,3"`
+		}{}
+
+		_, err := strum.Marshal(test)
+		require.ErrorContains(t, err, `cannot encode field "Val"`)
+	})
+
+	t.Run("error when the marshaler fails", func(t *testing.T) {
+		test := struct {
+			Val failingMarshaler `strum:"0,3"`
+		}{}
+
+		_, err := strum.Marshal(test)
+		require.ErrorContains(t, err, `cannot encode field "Val": test`)
+	})
+}
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) UnmarshalText([]byte) error {
+	return nil
+}
+
+func (failingMarshaler) MarshalText() ([]byte, error) {
+	return nil, errors.New("test")
+}
@@ -0,0 +1,228 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// WithRecordLength declares the length of records, overriding the "reclen" option of the struct
+// (see Validate).
+func WithRecordLength(length int) Option {
+	return func(o *options) {
+		o.recordLength = length
+	}
+}
+
+// WithStrictRecordLength makes Unmarshal reject lines whose length, in the unit of the indexes,
+// differs from the declared record length (see WithRecordLength and Validate).
+func WithStrictRecordLength() Option {
+	return func(o *options) {
+		o.strictLength = true
+	}
+}
+
+// Validate checks the layout of v, which must be a struct or a pointer to one, possibly nil. In
+// addition to the errors raised by Unmarshal and Marshal on invalid tags, such as negative or
+// inverted indexes and repeated groups wider than their field, it reports fields that overlap,
+// positions that are not mapped by any field, filler or blank field, and fields that do not fit
+// the declared record length. Nested structs are checked against the width of their field.
+//
+// The record length is declared either with WithRecordLength or with the "reclen" option on a
+// blank field of the struct, e.g. `_ struct{} strum:"reclen=80"`. Without one, only the gaps
+// between fields are reported.
+//
+// Every problem is reported, joined with errors.Join, and matches ErrInvalidLayout.
+func Validate(v any, opts ...Option) error {
+	options := *defaultOptions
+
+	for i := range opts {
+		opts[i](&options)
+	}
+
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %v", ErrNotStruct, t)
+	}
+
+	p, err := compile(t, &options)
+	if err != nil {
+		return err
+	}
+
+	return errors.Join(p.validate(p.length(&options), "", 0)...)
+}
+
+// length returns the declared record length, or 0.
+func (p *plan) length(o *options) int {
+	if o.recordLength > 0 {
+		return o.recordLength
+	}
+
+	return p.recordLength
+}
+
+// checkLength returns an error if line does not have the declared record length.
+func (p *plan) checkLength(line string, o *options) error {
+	length := p.length(o)
+	if length == 0 {
+		return fmt.Errorf("%w: no record length declared", ErrRecordLength)
+	}
+
+	u := o.indexUnit()
+
+	if n := u.measure(line); n != length {
+		return fmt.Errorf("%w: line of %d %s instead of %d", ErrRecordLength, n, u, length)
+	}
+
+	return nil
+}
+
+// layoutSpan is a range of indexes mapped by a field or a filler.
+type layoutSpan struct {
+	span
+	name string
+}
+
+func (s layoutSpan) String() string {
+	end := ""
+	if s.endIdx != -1 {
+		end = strconv.Itoa(s.endIdx)
+	}
+
+	return fmt.Sprintf("%s [%d:%s]", s.name, s.startIdx, end)
+}
+
+// validate returns the problems of the layout of p, whose records have the given length, or 0 if
+// unknown. Names are prefixed with prefix and indexes shifted by offset.
+func (p *plan) validate(length int, prefix string, offset int) []error {
+	errs, end := checkSpans(p.spans(prefix, offset), offset)
+
+	if length > 0 && end != -1 {
+		errs = append(errs, checkEnd(end, offset+length)...)
+	}
+
+	for i := range p.fields {
+		errs = append(errs, p.fields[i].validateNested(prefix, offset)...)
+	}
+
+	for i := range errs {
+		errs[i] = classify(ErrInvalidLayout, errs[i])
+	}
+
+	return errs
+}
+
+// validateNested returns the problems of the layout of the nested struct f, if any, whose
+// parent is named prefix and starts at offset.
+func (f *fieldPlan) validateNested(prefix string, offset int) []error {
+	if f.nested == nil || f.endIdx == -1 {
+		return nil
+	}
+
+	return f.nested.validate(f.endIdx-f.startIdx, prefix+f.name+".", offset+f.startIdx)
+}
+
+// checkSpans returns the overlaps and gaps between spans, sorted by start index, starting at
+// offset. It also returns the end index of the spans, or -1 if one extends to the end of the
+// line.
+func checkSpans(spans []layoutSpan, offset int) ([]error, int) {
+	var (
+		errs []error
+		// reach is the span that extends the furthest so far, if any, and end its end index
+		reach *layoutSpan
+		end   = offset
+	)
+
+	for i := range spans {
+		s := &spans[i]
+
+		if err := checkSpan(s, reach, end); err != nil {
+			errs = append(errs, err)
+		}
+
+		if end != -1 && (s.endIdx == -1 || s.endIdx > end) {
+			reach, end = s, s.endIdx
+		}
+	}
+
+	return errs, end
+}
+
+// checkSpan returns an error if s overlaps reach, the span that extends the furthest before it,
+// which ends at end, or if there is a gap between them.
+func checkSpan(s, reach *layoutSpan, end int) error {
+	switch {
+	case reach != nil && (end == -1 || s.startIdx < end):
+		return fmt.Errorf("%s overlaps %s", s, reach)
+	case s.startIdx > end:
+		return fmt.Errorf("positions [%d:%d] are not mapped", end, s.startIdx)
+	default:
+		return nil
+	}
+}
+
+// spans returns the ranges mapped by the fields and fillers of p, sorted by start index.
+func (p *plan) spans(prefix string, offset int) []layoutSpan {
+	spans := make([]layoutSpan, 0, len(p.fields)+len(p.fillers))
+
+	shift := func(s span) span {
+		s.startIdx += offset
+		if s.endIdx != -1 {
+			s.endIdx += offset
+		}
+
+		return s
+	}
+
+	for i := range p.fields {
+		f := &p.fields[i]
+		spans = append(spans, layoutSpan{
+			span: shift(span{f.startIdx, f.endIdx}),
+			name: fmt.Sprintf("field %q", prefix+f.name),
+		})
+	}
+
+	for _, filler := range p.fillers {
+		spans = append(spans, layoutSpan{span: shift(filler), name: "filler"})
+	}
+
+	slices.SortStableFunc(spans, func(a, b layoutSpan) int {
+		return cmp.Compare(a.startIdx, b.startIdx)
+	})
+
+	return spans
+}
+
+// checkEnd compares end, the end index of the last field, to the end of the record.
+func checkEnd(end, recordEnd int) []error {
+	switch {
+	case end < recordEnd:
+		return []error{fmt.Errorf("positions [%d:%d] are not mapped", end, recordEnd)}
+	case end > recordEnd:
+		return []error{fmt.Errorf("fields end at %d, past the end of the record at %d", end, recordEnd)}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2024 Terminal Stream Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strum_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/terminalstream/strum"
+)
+
+type validRecord struct {
+	_      struct{} `strum:"reclen=12"`
+	Code   string   `strum:"w=2"`
+	_      struct{} `strum:"skip=1"`
+	Amount int      `strum:"w=4"`
+	Nested struct {
+		A string `strum:"0,2"`
+		_ string `strum:"2,3"`
+	} `strum:"w=3"`
+	Flag string `strum:"w=2"`
+}
+
+func TestValidate(t *testing.T) { //nolint:funlen
+	t.Run("valid layout", func(t *testing.T) {
+		require.NoError(t, strum.Validate(validRecord{}))
+		require.NoError(t, strum.Validate((*validRecord)(nil)))
+	})
+
+	t.Run("reports every problem", func(t *testing.T) {
+		err := strum.Validate(&struct {
+			_      struct{} `strum:"reclen=20"`
+			Code   string   `strum:"0,2"`
+			Name   string   `strum:"1,5"`
+			Amount int      `strum:"8,12"`
+			Nested struct {
+				A string `strum:"0,2"`
+				B string `strum:"3,6"`
+			} `strum:"12,17"`
+		}{})
+		require.ErrorIs(t, err, strum.ErrInvalidLayout)
+		require.EqualError(t, err, `field "Name" [1:5] overlaps field "Code" [0:2]
+positions [5:8] are not mapped
+positions [17:20] are not mapped
+positions [14:15] are not mapped
+fields end at 18, past the end of the record at 17`)
+	})
+
+	t.Run("fields that extend to the end of the line", func(t *testing.T) {
+		err := strum.Validate(&struct {
+			Code string `strum:"0"`
+			Name string `strum:"2,5"`
+		}{})
+		require.EqualError(t, err, `field "Name" [2:5] overlaps field "Code" [0:]`)
+
+		require.NoError(t, strum.Validate(&struct {
+			Code string `strum:"0,2"`
+			Name string `strum:"2"`
+		}{}, strum.WithRecordLength(80)))
+	})
+
+	t.Run("record length option", func(t *testing.T) {
+		err := strum.Validate(validRecord{}, strum.WithRecordLength(10))
+		require.EqualError(t, err, "fields end at 12, past the end of the record at 10")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		err := strum.Validate(1)
+		require.ErrorIs(t, err, strum.ErrNotStruct)
+
+		err = strum.Validate(&struct {
+			Val string `strum:"reclen=2"`
+		}{})
+		require.ErrorIs(t, err, strum.ErrInvalidTag)
+		require.ErrorContains(t, err, `option "reclen" is only supported on blank fields`)
+
+		err = strum.Validate(&struct {
+			_ struct{} `strum:"0,reclen=2"`
+		}{})
+		require.ErrorContains(t, err, `option "reclen" cannot be combined with indexes or options`)
+	})
+
+	t.Run("invalid ranges", func(t *testing.T) {
+		err := strum.Validate(&struct {
+			A string `strum:"0,5"`
+			B string `strum:"3,8"`
+			C string `strum:"10,9"`
+		}{})
+		require.ErrorIs(t, err, strum.ErrInvalidTag)
+		require.EqualError(t, err, `format error on field "C" tagged strum:"10,9": `+
+			`end index must be greater or equal to start index: 9 < 10`)
+
+		err = strum.Validate(&struct {
+			A string `strum:"-2,3"`
+		}{})
+		require.ErrorIs(t, err, strum.ErrInvalidTag)
+		require.EqualError(t, err,
+			`format error on field "A" tagged strum:"-2,3": start index -2 must not be negative`)
+	})
+
+	t.Run("repeated groups wider than their field", func(t *testing.T) {
+		err := strum.Validate(&struct {
+			F []int `strum:"0,10,occurs=3,elem=4"`
+		}{})
+		require.ErrorIs(t, err, strum.ErrInvalidTag)
+		require.EqualError(t, err, `format error on field "F" tagged strum:"0,10,occurs=3,elem=4": `+
+			`3 occurrences of width 4 do not fit the field's width 10`)
+
+		require.NoError(t, strum.Validate(&struct {
+			F []int `strum:"0,12,occurs=3,elem=4"`
+		}{}))
+	})
+}
+
+func TestUnmarshal_strictRecordLength(t *testing.T) {
+	t.Run("accepts lines of the record length", func(t *testing.T) {
+		test := &validRecord{}
+
+		err := strum.Unmarshal("AB 0012xy ok", test, strum.WithStrictRecordLength())
+		require.NoError(t, err)
+		require.Equal(t, 12, test.Amount)
+	})
+
+	t.Run("rejects other lines", func(t *testing.T) {
+		err := strum.Unmarshal("AB 0012xy ok ", &validRecord{}, strum.WithStrictRecordLength())
+		require.ErrorIs(t, err, strum.ErrRecordLength)
+		require.ErrorContains(t, err, "line of 13 bytes instead of 12")
+
+		err = strum.Unmarshal(
+			"AB 0012xy ok", &validRecord{},
+			strum.WithStrictRecordLength(), strum.WithRecordLength(13),
+		)
+		require.ErrorContains(t, err, "line of 12 bytes instead of 13")
+	})
+
+	t.Run("error when no record length is declared", func(t *testing.T) {
+		err := strum.Unmarshal("a", &struct {
+			Val string `strum:"0"`
+		}{}, strum.WithStrictRecordLength())
+		require.ErrorIs(t, err, strum.ErrRecordLength)
+		require.ErrorContains(t, err, "no record length declared")
+	})
+}